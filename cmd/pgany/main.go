@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/tls"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/jerluc/pgany/pg"
+	"github.com/jerluc/pgany/pg/memhandler"
 )
 
 var (
@@ -41,8 +43,47 @@ var (
 		Value:    "info",
 		Usage:    "Set server log levels",
 	}
+	TLSCertFile = &cli.StringFlag{
+		Category: ServerCategory,
+		Name:     "tls-cert",
+		Usage:    "Path to a PEM-encoded certificate to offer clients that request SSL",
+	}
+	TLSKeyFile = &cli.StringFlag{
+		Category: ServerCategory,
+		Name:     "tls-key",
+		Usage:    "Path to the PEM-encoded private key for --tls-cert",
+	}
+	RequireTLS = &cli.BoolFlag{
+		Category: ServerCategory,
+		Name:     "require-tls",
+		Usage:    "Reject clients that do not negotiate TLS",
+	}
+	Handler = &cli.StringFlag{
+		Category: ServerCategory,
+		Name:     "handler",
+		Value:    "memory",
+		Usage:    "QueryHandler to serve queries with: \"memory\" (demo in-memory tables) or \"none\" (fake placeholder results)",
+	}
 )
 
+// demoMemoryHandler returns a memhandler.Handler seeded with a couple of
+// fixed tables, for exercising pgany end-to-end without a real backing
+// data source.
+func demoMemoryHandler() *memhandler.Handler {
+	return memhandler.New(map[string]memhandler.Table{
+		"greeting": {
+			Columns: pg.Schema{
+				{Name: "id", OID: 23},
+				{Name: "message", OID: 25},
+			},
+			Rows: []pg.Row{
+				{int32(1), "hello from pgany"},
+				{int32(2), "this table is in-memory demo data"},
+			},
+		},
+	})
+}
+
 func main() {
 	app := &cli.App{
 		Name:  "pgany",
@@ -50,6 +91,10 @@ func main() {
 		Flags: []cli.Flag{
 			BindAddress,
 			LogLevel,
+			TLSCertFile,
+			TLSKeyFile,
+			RequireTLS,
+			Handler,
 		},
 		Action: func(c *cli.Context) error {
 			log.SetFormatter(&log.TextFormatter{
@@ -63,8 +108,40 @@ func main() {
 			} else {
 				return cli.Exit(fmt.Sprintf("Invalid log level: %s", logLevelStr), 1)
 			}
+			var tlsConfig *tls.Config
+			certFile, keyFile := TLSCertFile.Get(c), TLSKeyFile.Get(c)
+			if certFile != "" || keyFile != "" {
+				cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("Failed to load TLS certificate: %s", err), 1)
+				}
+				tlsConfig = &tls.Config{
+					Certificates: []tls.Certificate{cert},
+					ClientAuth:   tls.RequestClientCert,
+				}
+			}
+			requireTLS := RequireTLS.Get(c)
+			if requireTLS && tlsConfig == nil {
+				return cli.Exit("--require-tls requires --tls-cert and --tls-key", 1)
+			}
+
+			var queryHandler pg.QueryHandler
+			switch handler := Handler.Get(c); handler {
+			case "memory":
+				queryHandler = demoMemoryHandler()
+			case "none":
+				queryHandler = nil
+			default:
+				return cli.Exit(fmt.Sprintf("Unknown --handler: %s", handler), 1)
+			}
+
 			addr := BindAddress.Get(c)
-			server, err := pg.NewPGProtoServer(addr)
+			server, err := pg.NewPGProtoServer(pg.PGProtoServerConfig{
+				BindAddress:  addr,
+				TLSConfig:    tlsConfig,
+				RequireTLS:   requireTLS,
+				QueryHandler: queryHandler,
+			})
 			if err != nil {
 				return cli.Exit(err, 1)
 			}
@@ -0,0 +1,23 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jerluc/pgany/pg/pgproto3"
+)
+
+// Authenticator authenticates a connection as part of the startup flow,
+// after the StartupMessage has been received and before the query loop
+// begins. Implementations exchange whatever messages their mechanism
+// requires directly over backend and must send AuthenticationOk themselves
+// on success.
+type Authenticator interface {
+	Authenticate(ctx context.Context, backend *pgproto3.Backend, user string) error
+}
+
+// CredentialStore looks up a user's stored SCRAM-SHA-256 credentials, in the
+// packed "<iterations>:<salt-b64>:<StoredKey-b64>:<ServerKey-b64>" format
+// produced by HashPassword.
+type CredentialStore interface {
+	Credentials(ctx context.Context, user string) (string, error)
+}
@@ -0,0 +1,46 @@
+package pg
+
+import "fmt"
+
+// Well-known SQLSTATE codes that clients (psql, JDBC, pgx, ...) key their
+// behavior off of. See https://www.postgresql.org/docs/current/errcodes-appendix.html.
+const (
+	SQLStateInternalError        = "XX000"
+	SQLStateSerializationFailure = "40001"
+	SQLStateDeadlockDetected     = "40P01"
+)
+
+// Error is a protocol-level error carrying the full set of fields
+// PostgreSQL's ErrorResponse message supports. QueryHandlers that want
+// clients to see a precise SQLSTATE (for example, so pgx/JDBC/psql apply
+// their standard serialization-failure retry logic) should return one of
+// these instead of a plain error.
+type Error struct {
+	Severity       string
+	Code           string
+	Message        string
+	Detail         string
+	Hint           string
+	Position       int32
+	Where          string
+	SchemaName     string
+	TableName      string
+	ColumnName     string
+	DataTypeName   string
+	ConstraintName string
+	File           string
+	Line           int32
+	Routine        string
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Message)
+	}
+	return e.Message
+}
+
+// Notice carries the same fields as Error but, like PostgreSQL's
+// NoticeResponse, is advisory: it does not interrupt the command that
+// produced it.
+type Notice Error
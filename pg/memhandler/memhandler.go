@@ -0,0 +1,89 @@
+// Package memhandler is a reference pg.QueryHandler backed by fixed,
+// in-memory tables. It understands only the simplest possible query shape
+// ("SELECT ... FROM <table>") and exists to demonstrate and exercise the
+// QueryHandler interface, not as a real SQL engine.
+package memhandler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jerluc/pgany/pg"
+)
+
+// Table is a fixed, in-memory result set that Handler can serve a query
+// against.
+type Table struct {
+	Columns pg.Schema
+	Rows    []pg.Row
+}
+
+// Handler is a pg.QueryHandler that serves queries against a fixed set of
+// named tables.
+type Handler struct {
+	tables map[string]Table
+}
+
+// New returns a Handler serving the given named tables.
+func New(tables map[string]Table) *Handler {
+	return &Handler{tables: tables}
+}
+
+// Query implements pg.QueryHandler by finding the FROM keyword in sql
+// (case-insensitively), looking up the word after it by exact table name
+// (quotes and a trailing semicolon trimmed), and returning that table's rows
+// in full. params is
+// ignored: memhandler's fixed tables take no parameters.
+func (h *Handler) Query(ctx context.Context, sess *pg.Session, sql string, params []pg.Param) (pg.Rows, error) {
+	table, err := h.resolveTable(sql)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{table: table, cursor: -1}, nil
+}
+
+func (h *Handler) resolveTable(sql string) (Table, error) {
+	fields := strings.Fields(sql)
+	for i, field := range fields {
+		if strings.EqualFold(field, "from") && i+1 < len(fields) {
+			name := strings.Trim(fields[i+1], `;"`)
+			table, ok := h.tables[name]
+			if !ok {
+				return Table{}, fmt.Errorf("memhandler: unknown table %q", name)
+			}
+			return table, nil
+		}
+	}
+	return Table{}, fmt.Errorf("memhandler: could not find a table name in query: %q", sql)
+}
+
+// rows is a pg.Rows cursor over a Table's fixed row slice.
+type rows struct {
+	table  Table
+	cursor int
+}
+
+func (r *rows) Columns() pg.Schema {
+	return r.table.Columns
+}
+
+func (r *rows) Next() bool {
+	if r.cursor+1 >= len(r.table.Rows) {
+		return false
+	}
+	r.cursor++
+	return true
+}
+
+func (r *rows) Values() pg.Row {
+	return r.table.Rows[r.cursor]
+}
+
+func (r *rows) Err() error {
+	return nil
+}
+
+func (r *rows) Close() error {
+	return nil
+}
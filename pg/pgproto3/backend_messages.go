@@ -0,0 +1,484 @@
+package pgproto3
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// AuthenticationOk signals that authentication succeeded.
+type AuthenticationOk struct{}
+
+func (dst *AuthenticationOk) Decode(src []byte) error {
+	if len(src) != 4 || binary.BigEndian.Uint32(src) != 0 {
+		return fmt.Errorf("invalid AuthenticationOk")
+	}
+	return nil
+}
+
+func (src *AuthenticationOk) Encode(dst []byte) []byte {
+	dst = append(dst, 'R')
+	dst = appendInt32(dst, 8)
+	dst = appendUint32(dst, 0)
+	return dst
+}
+
+// AuthenticationSASL lists the SASL mechanisms the server is willing to
+// negotiate.
+type AuthenticationSASL struct {
+	AuthMechanisms []string
+}
+
+func (dst *AuthenticationSASL) Decode(src []byte) error {
+	if len(src) < 4 || binary.BigEndian.Uint32(src) != 10 {
+		return fmt.Errorf("invalid AuthenticationSASL")
+	}
+	rp := 4
+	for rp < len(src) && src[rp] != 0 {
+		mech, n, err := readCString(src[rp:])
+		if err != nil {
+			return fmt.Errorf("invalid AuthenticationSASL mechanism: %w", err)
+		}
+		rp += n
+		dst.AuthMechanisms = append(dst.AuthMechanisms, mech)
+	}
+	return nil
+}
+
+func (src *AuthenticationSASL) Encode(dst []byte) []byte {
+	dst = append(dst, 'R')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = appendUint32(dst, 10)
+	for _, mech := range src.AuthMechanisms {
+		dst = appendCString(dst, mech)
+	}
+	dst = append(dst, 0)
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// AuthenticationSASLContinue carries a server's intermediate SASL challenge.
+type AuthenticationSASLContinue struct {
+	Data []byte
+}
+
+func (dst *AuthenticationSASLContinue) Decode(src []byte) error {
+	if len(src) < 4 || binary.BigEndian.Uint32(src) != 11 {
+		return fmt.Errorf("invalid AuthenticationSASLContinue")
+	}
+	dst.Data = src[4:]
+	return nil
+}
+
+func (src *AuthenticationSASLContinue) Encode(dst []byte) []byte {
+	dst = append(dst, 'R')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = appendUint32(dst, 11)
+	dst = append(dst, src.Data...)
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// AuthenticationSASLFinal carries the server's final SASL verifier.
+type AuthenticationSASLFinal struct {
+	Data []byte
+}
+
+func (dst *AuthenticationSASLFinal) Decode(src []byte) error {
+	if len(src) < 4 || binary.BigEndian.Uint32(src) != 12 {
+		return fmt.Errorf("invalid AuthenticationSASLFinal")
+	}
+	dst.Data = src[4:]
+	return nil
+}
+
+func (src *AuthenticationSASLFinal) Encode(dst []byte) []byte {
+	dst = append(dst, 'R')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = appendUint32(dst, 12)
+	dst = append(dst, src.Data...)
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// newAuthenticationMessage returns a concrete authentication message type
+// based on the sub-type code in the first 4 bytes of body, since all
+// Authentication* messages share the 'R' type byte.
+func newAuthenticationMessage(body []byte) (BackendMessage, error) {
+	if len(body) < 4 {
+		return nil, fmt.Errorf("invalid authentication message")
+	}
+	switch binary.BigEndian.Uint32(body) {
+	case 0:
+		return &AuthenticationOk{}, nil
+	case 10:
+		return &AuthenticationSASL{}, nil
+	case 11:
+		return &AuthenticationSASLContinue{}, nil
+	case 12:
+		return &AuthenticationSASLFinal{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported authentication message: %d", binary.BigEndian.Uint32(body))
+	}
+}
+
+// ParameterStatus reports the value of a run-time server parameter.
+type ParameterStatus struct {
+	Name  string
+	Value string
+}
+
+func (dst *ParameterStatus) Decode(src []byte) error {
+	name, n, err := readCString(src)
+	if err != nil {
+		return fmt.Errorf("invalid ParameterStatus name: %w", err)
+	}
+	value, _, err := readCString(src[n:])
+	if err != nil {
+		return fmt.Errorf("invalid ParameterStatus value: %w", err)
+	}
+	dst.Name = name
+	dst.Value = value
+	return nil
+}
+
+func (src *ParameterStatus) Encode(dst []byte) []byte {
+	dst = append(dst, 'S')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = appendCString(dst, src.Name)
+	dst = appendCString(dst, src.Value)
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// BackendKeyData carries the process ID and secret key a frontend needs to
+// issue a CancelRequest later.
+type BackendKeyData struct {
+	ProcessID uint32
+	SecretKey uint32
+}
+
+func (dst *BackendKeyData) Decode(src []byte) error {
+	if len(src) != 8 {
+		return fmt.Errorf("invalid BackendKeyData")
+	}
+	dst.ProcessID = binary.BigEndian.Uint32(src)
+	dst.SecretKey = binary.BigEndian.Uint32(src[4:])
+	return nil
+}
+
+func (src *BackendKeyData) Encode(dst []byte) []byte {
+	dst = append(dst, 'K')
+	dst = appendInt32(dst, 12)
+	dst = appendUint32(dst, src.ProcessID)
+	dst = appendUint32(dst, src.SecretKey)
+	return dst
+}
+
+// ReadyForQuery tells the frontend the server is ready for a new query and
+// reports the current transaction status.
+type ReadyForQuery struct {
+	TxStatus byte // 'I' idle, 'T' in a transaction, 'E' in a failed transaction
+}
+
+func (dst *ReadyForQuery) Decode(src []byte) error {
+	if len(src) != 1 {
+		return fmt.Errorf("invalid ReadyForQuery")
+	}
+	dst.TxStatus = src[0]
+	return nil
+}
+
+func (src *ReadyForQuery) Encode(dst []byte) []byte {
+	dst = append(dst, 'Z')
+	dst = appendInt32(dst, 5)
+	dst = append(dst, src.TxStatus)
+	return dst
+}
+
+// FieldDescription describes one column of a RowDescription.
+type FieldDescription struct {
+	Name                 string
+	TableOID             uint32
+	TableAttributeNumber uint16
+	DataTypeOID          uint32
+	DataTypeSize         int16
+	TypeModifier         int32
+	Format               int16
+}
+
+// RowDescription describes the columns of the rows that follow in response
+// to a query.
+type RowDescription struct {
+	Fields []FieldDescription
+}
+
+func (dst *RowDescription) Decode(src []byte) error {
+	if len(src) < 2 {
+		return fmt.Errorf("invalid RowDescription")
+	}
+	fieldCount := int(binary.BigEndian.Uint16(src))
+	rp := 2
+
+	dst.Fields = make([]FieldDescription, fieldCount)
+	for i := 0; i < fieldCount; i++ {
+		name, n, err := readCString(src[rp:])
+		if err != nil {
+			return fmt.Errorf("invalid RowDescription field name: %w", err)
+		}
+		rp += n
+
+		if len(src[rp:]) < 18 {
+			return fmt.Errorf("invalid RowDescription field")
+		}
+		fd := FieldDescription{
+			Name:                 name,
+			TableOID:             binary.BigEndian.Uint32(src[rp:]),
+			TableAttributeNumber: binary.BigEndian.Uint16(src[rp+4:]),
+			DataTypeOID:          binary.BigEndian.Uint32(src[rp+6:]),
+			DataTypeSize:         int16(binary.BigEndian.Uint16(src[rp+10:])),
+			TypeModifier:         int32(binary.BigEndian.Uint32(src[rp+12:])),
+			Format:               int16(binary.BigEndian.Uint16(src[rp+16:])),
+		}
+		rp += 18
+		dst.Fields[i] = fd
+	}
+
+	return nil
+}
+
+func (src *RowDescription) Encode(dst []byte) []byte {
+	dst = append(dst, 'T')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = appendInt16(dst, int16(len(src.Fields)))
+	for _, fd := range src.Fields {
+		dst = appendCString(dst, fd.Name)
+		dst = appendUint32(dst, fd.TableOID)
+		dst = appendUint16(dst, fd.TableAttributeNumber)
+		dst = appendUint32(dst, fd.DataTypeOID)
+		dst = appendInt16(dst, fd.DataTypeSize)
+		dst = appendInt32(dst, fd.TypeModifier)
+		dst = appendInt16(dst, fd.Format)
+	}
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// DataRow carries one row of query results. A nil entry in Values
+// represents SQL NULL.
+type DataRow struct {
+	Values [][]byte
+}
+
+func (dst *DataRow) Decode(src []byte) error {
+	if len(src) < 2 {
+		return fmt.Errorf("invalid DataRow")
+	}
+	valueCount := int(binary.BigEndian.Uint16(src))
+	rp := 2
+
+	dst.Values = make([][]byte, valueCount)
+	for i := 0; i < valueCount; i++ {
+		if len(src[rp:]) < 4 {
+			return fmt.Errorf("invalid DataRow value length")
+		}
+		valLen := int32(binary.BigEndian.Uint32(src[rp:]))
+		rp += 4
+		if valLen == -1 {
+			dst.Values[i] = nil
+			continue
+		}
+		if valLen < -1 || len(src[rp:]) < int(valLen) {
+			return fmt.Errorf("invalid DataRow value")
+		}
+		dst.Values[i] = src[rp : rp+int(valLen)]
+		rp += int(valLen)
+	}
+
+	return nil
+}
+
+func (src *DataRow) Encode(dst []byte) []byte {
+	dst = append(dst, 'D')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = appendInt16(dst, int16(len(src.Values)))
+	for _, v := range src.Values {
+		if v == nil {
+			dst = appendInt32(dst, -1)
+			continue
+		}
+		dst = appendInt32(dst, int32(len(v)))
+		dst = append(dst, v...)
+	}
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// CommandComplete reports that a query completed successfully along with a
+// command tag such as "SELECT 2".
+type CommandComplete struct {
+	CommandTag string
+}
+
+func (dst *CommandComplete) Decode(src []byte) error {
+	tag, _, err := readCString(src)
+	if err != nil {
+		return fmt.Errorf("invalid CommandComplete: %w", err)
+	}
+	dst.CommandTag = tag
+	return nil
+}
+
+func (src *CommandComplete) Encode(dst []byte) []byte {
+	dst = append(dst, 'C')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = appendCString(dst, src.CommandTag)
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// EmptyQueryResponse is sent in place of a CommandComplete when the query
+// string was empty.
+type EmptyQueryResponse struct{}
+
+func (dst *EmptyQueryResponse) Decode(src []byte) error {
+	if len(src) != 0 {
+		return fmt.Errorf("invalid EmptyQueryResponse")
+	}
+	return nil
+}
+
+func (src *EmptyQueryResponse) Encode(dst []byte) []byte {
+	return append(dst, 'I', 0, 0, 0, 4)
+}
+
+// ParseComplete confirms a Parse message was processed.
+type ParseComplete struct{}
+
+func (dst *ParseComplete) Decode(src []byte) error {
+	if len(src) != 0 {
+		return fmt.Errorf("invalid ParseComplete")
+	}
+	return nil
+}
+
+func (src *ParseComplete) Encode(dst []byte) []byte {
+	return append(dst, '1', 0, 0, 0, 4)
+}
+
+// BindComplete confirms a Bind message was processed.
+type BindComplete struct{}
+
+func (dst *BindComplete) Decode(src []byte) error {
+	if len(src) != 0 {
+		return fmt.Errorf("invalid BindComplete")
+	}
+	return nil
+}
+
+func (src *BindComplete) Encode(dst []byte) []byte {
+	return append(dst, '2', 0, 0, 0, 4)
+}
+
+// CloseComplete confirms a Close message was processed.
+type CloseComplete struct{}
+
+func (dst *CloseComplete) Decode(src []byte) error {
+	if len(src) != 0 {
+		return fmt.Errorf("invalid CloseComplete")
+	}
+	return nil
+}
+
+func (src *CloseComplete) Encode(dst []byte) []byte {
+	return append(dst, '3', 0, 0, 0, 4)
+}
+
+// NoData is sent in place of a RowDescription when a described statement or
+// portal returns no rows.
+type NoData struct{}
+
+func (dst *NoData) Decode(src []byte) error {
+	if len(src) != 0 {
+		return fmt.Errorf("invalid NoData")
+	}
+	return nil
+}
+
+func (src *NoData) Encode(dst []byte) []byte {
+	return append(dst, 'n', 0, 0, 0, 4)
+}
+
+// ParameterDescription describes the types of a prepared statement's
+// parameters.
+type ParameterDescription struct {
+	ParameterOIDs []uint32
+}
+
+func (dst *ParameterDescription) Decode(src []byte) error {
+	if len(src) < 2 {
+		return fmt.Errorf("invalid ParameterDescription")
+	}
+	paramCount := int(binary.BigEndian.Uint16(src))
+	rp := 2
+
+	dst.ParameterOIDs = make([]uint32, paramCount)
+	for i := 0; i < paramCount; i++ {
+		if len(src[rp:]) < 4 {
+			return fmt.Errorf("invalid ParameterDescription OID")
+		}
+		dst.ParameterOIDs[i] = binary.BigEndian.Uint32(src[rp:])
+		rp += 4
+	}
+
+	return nil
+}
+
+func (src *ParameterDescription) Encode(dst []byte) []byte {
+	dst = append(dst, 't')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = appendUint16(dst, uint16(len(src.ParameterOIDs)))
+	for _, oid := range src.ParameterOIDs {
+		dst = appendUint32(dst, oid)
+	}
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// PortalSuspended reports that an Execute's row limit was reached before the
+// portal was exhausted.
+type PortalSuspended struct{}
+
+func (dst *PortalSuspended) Decode(src []byte) error {
+	if len(src) != 0 {
+		return fmt.Errorf("invalid PortalSuspended")
+	}
+	return nil
+}
+
+func (src *PortalSuspended) Encode(dst []byte) []byte {
+	return append(dst, 's', 0, 0, 0, 4)
+}
@@ -0,0 +1,44 @@
+package pgproto3
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// chunkReader reads the standard PostgreSQL message framing (a 1-byte type
+// tag, a 4-byte big-endian length counted from the start of the length
+// field, and a body) off of an underlying reader.
+type chunkReader struct {
+	r   io.Reader
+	buf []byte
+}
+
+func newChunkReader(r io.Reader) *chunkReader {
+	return &chunkReader{r: r}
+}
+
+// next reads the header and body of the next message. The returned body
+// slice is only valid until the next call to next.
+func (cr *chunkReader) next() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(cr.r, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	msgType := header[0]
+	length := int(binary.BigEndian.Uint32(header[1:])) - 4
+	if length < 0 {
+		return 0, nil, fmt.Errorf("invalid message length: %d", length)
+	}
+
+	if cap(cr.buf) < length {
+		cr.buf = make([]byte, length)
+	}
+	body := cr.buf[:length]
+	if _, err := io.ReadFull(cr.r, body); err != nil {
+		return 0, nil, err
+	}
+
+	return msgType, body, nil
+}
@@ -0,0 +1,178 @@
+package pgproto3
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Frontend is used by a client to send FrontendMessages to, and receive
+// BackendMessages from, a PostgreSQL server.
+type Frontend struct {
+	cr *chunkReader
+	w  io.Writer
+}
+
+func NewFrontend(r io.Reader, w io.Writer) *Frontend {
+	return &Frontend{cr: newChunkReader(r), w: w}
+}
+
+// Send encodes and writes msg to the server.
+func (f *Frontend) Send(msg FrontendMessage) error {
+	_, err := f.w.Write(msg.Encode(nil))
+	return err
+}
+
+// Receive reads and decodes the next message from the server. The returned
+// message is only valid until the next call to Receive.
+func (f *Frontend) Receive() (BackendMessage, error) {
+	msgType, body, err := f.cr.next()
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := newBackendMessage(msgType, body)
+	if err != nil {
+		return nil, err
+	}
+	if err := msg.Decode(body); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func newBackendMessage(msgType byte, body []byte) (BackendMessage, error) {
+	switch msgType {
+	case 'R':
+		return newAuthenticationMessage(body)
+	case 'S':
+		return &ParameterStatus{}, nil
+	case 'K':
+		return &BackendKeyData{}, nil
+	case 'Z':
+		return &ReadyForQuery{}, nil
+	case 'T':
+		return &RowDescription{}, nil
+	case 'D':
+		return &DataRow{}, nil
+	case 'C':
+		return &CommandComplete{}, nil
+	case 'E':
+		return &ErrorResponse{}, nil
+	case 'N':
+		return &NoticeResponse{}, nil
+	case 'I':
+		return &EmptyQueryResponse{}, nil
+	case '1':
+		return &ParseComplete{}, nil
+	case '2':
+		return &BindComplete{}, nil
+	case '3':
+		return &CloseComplete{}, nil
+	case 'n':
+		return &NoData{}, nil
+	case 't':
+		return &ParameterDescription{}, nil
+	case 's':
+		return &PortalSuspended{}, nil
+	default:
+		return nil, fmt.Errorf("unknown backend message type: %q", string(msgType))
+	}
+}
+
+// Backend is used by a server to receive FrontendMessages from, and send
+// BackendMessages to, a PostgreSQL client.
+type Backend struct {
+	cr *chunkReader
+	w  io.Writer
+}
+
+func NewBackend(r io.Reader, w io.Writer) *Backend {
+	return &Backend{cr: newChunkReader(r), w: w}
+}
+
+// Send encodes and writes msg to the client.
+func (b *Backend) Send(msg BackendMessage) error {
+	_, err := b.w.Write(msg.Encode(nil))
+	return err
+}
+
+// ReceiveStartupMessage reads the untyped length-prefixed message that opens
+// a connection, which is either a StartupMessage or an SSLRequest.
+func (b *Backend) ReceiveStartupMessage() (FrontendMessage, error) {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(b.cr.r, lenBuf[:]); err != nil {
+		return nil, err
+	}
+	length := int(binary.BigEndian.Uint32(lenBuf[:])) - 4
+	if length < 4 {
+		return nil, fmt.Errorf("invalid startup message length: %d", length)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(b.cr.r, body); err != nil {
+		return nil, err
+	}
+
+	code := binary.BigEndian.Uint32(body)
+	var msg FrontendMessage
+	switch code {
+	case ProtocolVersionNumber:
+		msg = &StartupMessage{}
+	case sslRequestCode:
+		msg = &SSLRequest{}
+	default:
+		return nil, fmt.Errorf("unknown startup protocol version: %d", code)
+	}
+	if err := msg.Decode(body); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// ReceiveRaw reads the next message's type byte and body without decoding
+// it into a concrete FrontendMessage. It exists for messages such as 'p'
+// whose body format depends on protocol state (password vs. SASL initial
+// response vs. SASL response) rather than the type byte alone — callers
+// like the SCRAM authenticator parse the body themselves. The returned body
+// is only valid until the next call to ReceiveRaw or Receive.
+func (b *Backend) ReceiveRaw() (byte, []byte, error) {
+	return b.cr.next()
+}
+
+// Receive reads and decodes the next message from the client. The returned
+// message is only valid until the next call to Receive.
+func (b *Backend) Receive() (FrontendMessage, error) {
+	msgType, body, err := b.cr.next()
+	if err != nil {
+		return nil, err
+	}
+
+	var msg FrontendMessage
+	switch msgType {
+	case 'p':
+		msg = &PasswordMessage{}
+	case 'Q':
+		msg = &Query{}
+	case 'P':
+		msg = &Parse{}
+	case 'B':
+		msg = &Bind{}
+	case 'D':
+		msg = &Describe{}
+	case 'E':
+		msg = &Execute{}
+	case 'C':
+		msg = &Close{}
+	case 'S':
+		msg = &Sync{}
+	case 'X':
+		msg = &Terminate{}
+	default:
+		return nil, fmt.Errorf("unknown frontend message type: %q", string(msgType))
+	}
+	if err := msg.Decode(body); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,248 @@
+package pgproto3
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Field tags used within ErrorResponse and NoticeResponse bodies. See
+// https://www.postgresql.org/docs/current/protocol-error-fields.html
+const (
+	fieldSeverity         = 'S'
+	fieldSeverityV        = 'V'
+	fieldCode             = 'C'
+	fieldMessage          = 'M'
+	fieldDetail           = 'D'
+	fieldHint             = 'H'
+	fieldPosition         = 'P'
+	fieldInternalPosition = 'p'
+	fieldInternalQuery    = 'q'
+	fieldWhere            = 'W'
+	fieldSchemaName       = 's'
+	fieldTableName        = 't'
+	fieldColumnName       = 'c'
+	fieldDataTypeName     = 'd'
+	fieldConstraintName   = 'n'
+	fieldFile             = 'F'
+	fieldLine             = 'L'
+	fieldRoutine          = 'R'
+)
+
+// notice is the field set shared by ErrorResponse and NoticeResponse; the two
+// differ only in their leading message type byte.
+type notice struct {
+	Severity         string
+	SeverityV        string
+	Code             string
+	Message          string
+	Detail           string
+	Hint             string
+	Position         int32
+	InternalPosition int32
+	InternalQuery    string
+	Where            string
+	SchemaName       string
+	TableName        string
+	ColumnName       string
+	DataTypeName     string
+	ConstraintName   string
+	File             string
+	Line             int32
+	Routine          string
+	UnknownFields    map[byte]string
+}
+
+func (dst *notice) decode(src []byte) error {
+	rp := 0
+	for rp < len(src) {
+		fieldType := src[rp]
+		rp++
+		if fieldType == 0 {
+			return nil
+		}
+
+		value, n, err := readCString(src[rp:])
+		if err != nil {
+			return fmt.Errorf("invalid field %q: %w", string(fieldType), err)
+		}
+		rp += n
+
+		switch fieldType {
+		case fieldSeverity:
+			dst.Severity = value
+		case fieldSeverityV:
+			dst.SeverityV = value
+		case fieldCode:
+			dst.Code = value
+		case fieldMessage:
+			dst.Message = value
+		case fieldDetail:
+			dst.Detail = value
+		case fieldHint:
+			dst.Hint = value
+		case fieldPosition:
+			dst.Position = parseInt32Field(value)
+		case fieldInternalPosition:
+			dst.InternalPosition = parseInt32Field(value)
+		case fieldInternalQuery:
+			dst.InternalQuery = value
+		case fieldWhere:
+			dst.Where = value
+		case fieldSchemaName:
+			dst.SchemaName = value
+		case fieldTableName:
+			dst.TableName = value
+		case fieldColumnName:
+			dst.ColumnName = value
+		case fieldDataTypeName:
+			dst.DataTypeName = value
+		case fieldConstraintName:
+			dst.ConstraintName = value
+		case fieldFile:
+			dst.File = value
+		case fieldLine:
+			dst.Line = parseInt32Field(value)
+		case fieldRoutine:
+			dst.Routine = value
+		default:
+			if dst.UnknownFields == nil {
+				dst.UnknownFields = make(map[byte]string)
+			}
+			dst.UnknownFields[fieldType] = value
+		}
+	}
+	return fmt.Errorf("notice fields missing terminator")
+}
+
+func parseInt32Field(s string) int32 {
+	var n int32
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0
+		}
+		n = n*10 + int32(r-'0')
+	}
+	return n
+}
+
+func formatInt32Field(n int32) string {
+	return fmt.Sprintf("%d", n)
+}
+
+func (src *notice) encode(dst []byte) []byte {
+	if src.Severity != "" {
+		dst = append(dst, fieldSeverity)
+		dst = appendCString(dst, src.Severity)
+	}
+	if src.SeverityV != "" {
+		dst = append(dst, fieldSeverityV)
+		dst = appendCString(dst, src.SeverityV)
+	}
+	if src.Code != "" {
+		dst = append(dst, fieldCode)
+		dst = appendCString(dst, src.Code)
+	}
+	if src.Message != "" {
+		dst = append(dst, fieldMessage)
+		dst = appendCString(dst, src.Message)
+	}
+	if src.Detail != "" {
+		dst = append(dst, fieldDetail)
+		dst = appendCString(dst, src.Detail)
+	}
+	if src.Hint != "" {
+		dst = append(dst, fieldHint)
+		dst = appendCString(dst, src.Hint)
+	}
+	if src.Position != 0 {
+		dst = append(dst, fieldPosition)
+		dst = appendCString(dst, formatInt32Field(src.Position))
+	}
+	if src.InternalPosition != 0 {
+		dst = append(dst, fieldInternalPosition)
+		dst = appendCString(dst, formatInt32Field(src.InternalPosition))
+	}
+	if src.InternalQuery != "" {
+		dst = append(dst, fieldInternalQuery)
+		dst = appendCString(dst, src.InternalQuery)
+	}
+	if src.Where != "" {
+		dst = append(dst, fieldWhere)
+		dst = appendCString(dst, src.Where)
+	}
+	if src.SchemaName != "" {
+		dst = append(dst, fieldSchemaName)
+		dst = appendCString(dst, src.SchemaName)
+	}
+	if src.TableName != "" {
+		dst = append(dst, fieldTableName)
+		dst = appendCString(dst, src.TableName)
+	}
+	if src.ColumnName != "" {
+		dst = append(dst, fieldColumnName)
+		dst = appendCString(dst, src.ColumnName)
+	}
+	if src.DataTypeName != "" {
+		dst = append(dst, fieldDataTypeName)
+		dst = appendCString(dst, src.DataTypeName)
+	}
+	if src.ConstraintName != "" {
+		dst = append(dst, fieldConstraintName)
+		dst = appendCString(dst, src.ConstraintName)
+	}
+	if src.File != "" {
+		dst = append(dst, fieldFile)
+		dst = appendCString(dst, src.File)
+	}
+	if src.Line != 0 {
+		dst = append(dst, fieldLine)
+		dst = appendCString(dst, formatInt32Field(src.Line))
+	}
+	if src.Routine != "" {
+		dst = append(dst, fieldRoutine)
+		dst = appendCString(dst, src.Routine)
+	}
+	for fieldType, value := range src.UnknownFields {
+		dst = append(dst, fieldType)
+		dst = appendCString(dst, value)
+	}
+	return append(dst, 0)
+}
+
+// ErrorResponse reports that a request could not be completed. Field names
+// follow https://www.postgresql.org/docs/current/protocol-error-fields.html.
+type ErrorResponse notice
+
+func (dst *ErrorResponse) Decode(src []byte) error {
+	return (*notice)(dst).decode(src)
+}
+
+func (src *ErrorResponse) Encode(dst []byte) []byte {
+	dst = append(dst, 'E')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = (*notice)(src).encode(dst)
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// NoticeResponse is an advisory message, using the same field layout as
+// ErrorResponse, that does not interrupt the current command.
+type NoticeResponse notice
+
+func (dst *NoticeResponse) Decode(src []byte) error {
+	return (*notice)(dst).decode(src)
+}
+
+func (src *NoticeResponse) Encode(dst []byte) []byte {
+	dst = append(dst, 'N')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = (*notice)(src).encode(dst)
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
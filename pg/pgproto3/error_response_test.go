@@ -0,0 +1,59 @@
+package pgproto3
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestErrorResponseNoticeResponseRoundTrip(t *testing.T) {
+	errResp := &ErrorResponse{
+		Severity:     "ERROR",
+		SeverityV:    "ERROR",
+		Code:         "40001",
+		Message:      "could not serialize access due to concurrent update",
+		Detail:       "Transaction 123 conflicted with transaction 456.",
+		Hint:         "retry the transaction",
+		Position:     0,
+		Where:        "SQL function \"f\" statement 1",
+		SchemaName:   "public",
+		TableName:    "accounts",
+		ColumnName:   "balance",
+		DataTypeName: "numeric",
+		File:         "postgres.c",
+		Line:         1234,
+		Routine:      "PostgresMain",
+	}
+	noticeResp := (*NoticeResponse)(&notice{
+		Severity: "NOTICE",
+		Code:     "00000",
+		Message:  "identifier truncated",
+	})
+
+	var buf bytes.Buffer
+	backend := NewBackend(nil, &buf)
+	if err := backend.Send(errResp); err != nil {
+		t.Fatalf("Send(ErrorResponse): %v", err)
+	}
+	if err := backend.Send(noticeResp); err != nil {
+		t.Fatalf("Send(NoticeResponse): %v", err)
+	}
+
+	frontend := NewFrontend(&buf, nil)
+
+	gotErr, err := frontend.Receive()
+	if err != nil {
+		t.Fatalf("Receive(ErrorResponse): %v", err)
+	}
+	if !reflect.DeepEqual(gotErr, errResp) {
+		t.Errorf("ErrorResponse round-trip mismatch:\n got  %+v\n want %+v", gotErr, errResp)
+	}
+
+	gotNotice, err := frontend.Receive()
+	if err != nil {
+		t.Fatalf("Receive(NoticeResponse): %v", err)
+	}
+	if !reflect.DeepEqual(gotNotice, noticeResp) {
+		t.Errorf("NoticeResponse round-trip mismatch:\n got  %+v\n want %+v", gotNotice, noticeResp)
+	}
+}
@@ -0,0 +1,352 @@
+package pgproto3
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// PasswordMessage carries a cleartext password, an MD5 hash, or (when used
+// as part of SASL authentication) a mechanism-specific response.
+type PasswordMessage struct {
+	Password string
+}
+
+func (dst *PasswordMessage) Decode(src []byte) error {
+	s, _, err := readCString(src)
+	if err != nil {
+		return fmt.Errorf("invalid password message: %w", err)
+	}
+	dst.Password = s
+	return nil
+}
+
+func (src *PasswordMessage) Encode(dst []byte) []byte {
+	dst = append(dst, 'p')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = appendCString(dst, src.Password)
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// Query is a simple query protocol request.
+type Query struct {
+	String string
+}
+
+func (dst *Query) Decode(src []byte) error {
+	s, _, err := readCString(src)
+	if err != nil {
+		return fmt.Errorf("invalid query message: %w", err)
+	}
+	dst.String = s
+	return nil
+}
+
+func (src *Query) Encode(dst []byte) []byte {
+	dst = append(dst, 'Q')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = appendCString(dst, src.String)
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// Parse creates a prepared statement from a parameterized SQL string.
+type Parse struct {
+	Name          string
+	Query         string
+	ParameterOIDs []uint32
+}
+
+func (dst *Parse) Decode(src []byte) error {
+	rp := 0
+
+	name, n, err := readCString(src[rp:])
+	if err != nil {
+		return fmt.Errorf("invalid Parse statement name: %w", err)
+	}
+	rp += n
+	dst.Name = name
+
+	query, n, err := readCString(src[rp:])
+	if err != nil {
+		return fmt.Errorf("invalid Parse query: %w", err)
+	}
+	rp += n
+	dst.Query = query
+
+	if len(src[rp:]) < 2 {
+		return fmt.Errorf("invalid Parse parameter count")
+	}
+	paramCount := int(binary.BigEndian.Uint16(src[rp:]))
+	rp += 2
+
+	dst.ParameterOIDs = make([]uint32, paramCount)
+	for i := 0; i < paramCount; i++ {
+		if len(src[rp:]) < 4 {
+			return fmt.Errorf("invalid Parse parameter OID")
+		}
+		dst.ParameterOIDs[i] = binary.BigEndian.Uint32(src[rp:])
+		rp += 4
+	}
+
+	return nil
+}
+
+func (src *Parse) Encode(dst []byte) []byte {
+	dst = append(dst, 'P')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = appendCString(dst, src.Name)
+	dst = appendCString(dst, src.Query)
+	dst = appendUint16(dst, uint16(len(src.ParameterOIDs)))
+	for _, oid := range src.ParameterOIDs {
+		dst = appendUint32(dst, oid)
+	}
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// Bind creates a portal from a prepared statement and a set of parameter
+// values.
+type Bind struct {
+	DestinationPortal    string
+	PreparedStatement    string
+	ParameterFormatCodes []int16
+	Parameters           [][]byte
+	ResultFormatCodes    []int16
+}
+
+func (dst *Bind) Decode(src []byte) error {
+	rp := 0
+
+	portal, n, err := readCString(src[rp:])
+	if err != nil {
+		return fmt.Errorf("invalid Bind destination portal: %w", err)
+	}
+	rp += n
+	dst.DestinationPortal = portal
+
+	stmt, n, err := readCString(src[rp:])
+	if err != nil {
+		return fmt.Errorf("invalid Bind prepared statement: %w", err)
+	}
+	rp += n
+	dst.PreparedStatement = stmt
+
+	if len(src[rp:]) < 2 {
+		return fmt.Errorf("invalid Bind parameter format count")
+	}
+	paramFormatCount := int(binary.BigEndian.Uint16(src[rp:]))
+	rp += 2
+	dst.ParameterFormatCodes = make([]int16, paramFormatCount)
+	for i := 0; i < paramFormatCount; i++ {
+		if len(src[rp:]) < 2 {
+			return fmt.Errorf("invalid Bind parameter format code")
+		}
+		dst.ParameterFormatCodes[i] = int16(binary.BigEndian.Uint16(src[rp:]))
+		rp += 2
+	}
+
+	if len(src[rp:]) < 2 {
+		return fmt.Errorf("invalid Bind parameter count")
+	}
+	paramCount := int(binary.BigEndian.Uint16(src[rp:]))
+	rp += 2
+	dst.Parameters = make([][]byte, paramCount)
+	for i := 0; i < paramCount; i++ {
+		if len(src[rp:]) < 4 {
+			return fmt.Errorf("invalid Bind parameter length")
+		}
+		paramLen := int32(binary.BigEndian.Uint32(src[rp:]))
+		rp += 4
+		if paramLen == -1 {
+			dst.Parameters[i] = nil
+			continue
+		}
+		if paramLen < 0 || len(src[rp:]) < int(paramLen) {
+			return fmt.Errorf("invalid Bind parameter value")
+		}
+		dst.Parameters[i] = src[rp : rp+int(paramLen)]
+		rp += int(paramLen)
+	}
+
+	if len(src[rp:]) < 2 {
+		return fmt.Errorf("invalid Bind result format count")
+	}
+	resultFormatCount := int(binary.BigEndian.Uint16(src[rp:]))
+	rp += 2
+	dst.ResultFormatCodes = make([]int16, resultFormatCount)
+	for i := 0; i < resultFormatCount; i++ {
+		if len(src[rp:]) < 2 {
+			return fmt.Errorf("invalid Bind result format code")
+		}
+		dst.ResultFormatCodes[i] = int16(binary.BigEndian.Uint16(src[rp:]))
+		rp += 2
+	}
+
+	return nil
+}
+
+func (src *Bind) Encode(dst []byte) []byte {
+	dst = append(dst, 'B')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = appendCString(dst, src.DestinationPortal)
+	dst = appendCString(dst, src.PreparedStatement)
+
+	dst = appendUint16(dst, uint16(len(src.ParameterFormatCodes)))
+	for _, fc := range src.ParameterFormatCodes {
+		dst = appendInt16(dst, fc)
+	}
+
+	dst = appendUint16(dst, uint16(len(src.Parameters)))
+	for _, p := range src.Parameters {
+		if p == nil {
+			dst = appendInt32(dst, -1)
+			continue
+		}
+		dst = appendInt32(dst, int32(len(p)))
+		dst = append(dst, p...)
+	}
+
+	dst = appendUint16(dst, uint16(len(src.ResultFormatCodes)))
+	for _, fc := range src.ResultFormatCodes {
+		dst = appendInt16(dst, fc)
+	}
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// Describe requests a ParameterDescription/RowDescription (for a prepared
+// statement) or a RowDescription (for a portal).
+type Describe struct {
+	ObjectType byte // 'S' for prepared statement, 'P' for portal
+	Name       string
+}
+
+func (dst *Describe) Decode(src []byte) error {
+	if len(src) < 1 {
+		return fmt.Errorf("invalid Describe message")
+	}
+	dst.ObjectType = src[0]
+	name, _, err := readCString(src[1:])
+	if err != nil {
+		return fmt.Errorf("invalid Describe name: %w", err)
+	}
+	dst.Name = name
+	return nil
+}
+
+func (src *Describe) Encode(dst []byte) []byte {
+	dst = append(dst, 'D')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = append(dst, src.ObjectType)
+	dst = appendCString(dst, src.Name)
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// Execute runs a bound portal, optionally limiting the number of rows
+// returned.
+type Execute struct {
+	Portal  string
+	MaxRows uint32
+}
+
+func (dst *Execute) Decode(src []byte) error {
+	portal, n, err := readCString(src)
+	if err != nil {
+		return fmt.Errorf("invalid Execute portal: %w", err)
+	}
+	if len(src[n:]) < 4 {
+		return fmt.Errorf("invalid Execute max rows")
+	}
+	dst.Portal = portal
+	dst.MaxRows = binary.BigEndian.Uint32(src[n:])
+	return nil
+}
+
+func (src *Execute) Encode(dst []byte) []byte {
+	dst = append(dst, 'E')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = appendCString(dst, src.Portal)
+	dst = appendUint32(dst, src.MaxRows)
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// Close destroys a named prepared statement or portal.
+type Close struct {
+	ObjectType byte // 'S' for prepared statement, 'P' for portal
+	Name       string
+}
+
+func (dst *Close) Decode(src []byte) error {
+	if len(src) < 1 {
+		return fmt.Errorf("invalid Close message")
+	}
+	dst.ObjectType = src[0]
+	name, _, err := readCString(src[1:])
+	if err != nil {
+		return fmt.Errorf("invalid Close name: %w", err)
+	}
+	dst.Name = name
+	return nil
+}
+
+func (src *Close) Encode(dst []byte) []byte {
+	dst = append(dst, 'C')
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = append(dst, src.ObjectType)
+	dst = appendCString(dst, src.Name)
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+	return dst
+}
+
+// Sync marks the end of an extended query message stream, flushing any
+// pending results and clearing session error state.
+type Sync struct{}
+
+func (dst *Sync) Decode(src []byte) error {
+	if len(src) != 0 {
+		return fmt.Errorf("invalid Sync message")
+	}
+	return nil
+}
+
+func (src *Sync) Encode(dst []byte) []byte {
+	return append(dst, 'S', 0, 0, 0, 4)
+}
+
+// Terminate politely ends a connection.
+type Terminate struct{}
+
+func (dst *Terminate) Decode(src []byte) error {
+	if len(src) != 0 {
+		return fmt.Errorf("invalid Terminate message")
+	}
+	return nil
+}
+
+func (src *Terminate) Encode(dst []byte) []byte {
+	return append(dst, 'X', 0, 0, 0, 4)
+}
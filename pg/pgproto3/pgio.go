@@ -0,0 +1,42 @@
+package pgproto3
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+func appendInt16(dst []byte, n int16) []byte {
+	return binary.BigEndian.AppendUint16(dst, uint16(n))
+}
+
+func appendInt32(dst []byte, n int32) []byte {
+	return binary.BigEndian.AppendUint32(dst, uint32(n))
+}
+
+func appendUint16(dst []byte, n uint16) []byte {
+	return binary.BigEndian.AppendUint16(dst, n)
+}
+
+func appendUint32(dst []byte, n uint32) []byte {
+	return binary.BigEndian.AppendUint32(dst, n)
+}
+
+// appendCString appends s to dst followed by a terminating zero byte.
+func appendCString(dst []byte, s string) []byte {
+	dst = append(dst, s...)
+	return append(dst, 0)
+}
+
+// readCString reads a zero-terminated string from the front of src and
+// returns it along with the number of bytes consumed, including the
+// terminator.
+func readCString(src []byte) (string, int, error) {
+	end := 0
+	for end < len(src) && src[end] != 0 {
+		end++
+	}
+	if end == len(src) {
+		return "", 0, fmt.Errorf("unterminated string")
+	}
+	return string(src[:end]), end + 1, nil
+}
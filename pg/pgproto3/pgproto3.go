@@ -0,0 +1,41 @@
+// Package pgproto3 implements encoding and decoding of the PostgreSQL wire
+// protocol version 3, as used by libpq and all modern PostgreSQL clients and
+// servers.
+//
+// Each message type is a concrete Go type implementing either
+// FrontendMessage, BackendMessage, or both. Frontend and Backend wrap an
+// io.Reader/io.Writer pair and take care of framing: reading the type byte
+// and length prefix, decoding into the right concrete type, and writing the
+// encoded bytes back out.
+package pgproto3
+
+// FrontendMessage is a message sent from a client to a server.
+type FrontendMessage interface {
+	// Decode parses src, the body of the message following the initial type
+	// byte and length (for messages that have a type byte), into the
+	// receiver. The receiver may keep slices of src, so it is only valid for
+	// as long as src is left unchanged.
+	Decode(src []byte) error
+
+	// Encode appends the wire representation of the message to dst and
+	// returns the resulting slice.
+	Encode(dst []byte) []byte
+}
+
+// BackendMessage is a message sent from a server to a client.
+type BackendMessage interface {
+	Decode(src []byte) error
+	Encode(dst []byte) []byte
+}
+
+// Frontend-only startup-phase protocol codes. These occupy the 4 bytes right
+// after the length of a startup-phase message, which is how a server tells an
+// SSLRequest apart from a StartupMessage before any type byte exists on the
+// wire.
+const (
+	// ProtocolVersionNumber is the protocol version number for the only
+	// protocol version pgany supports (3.0).
+	ProtocolVersionNumber uint32 = 196608
+
+	sslRequestCode uint32 = 80877103
+)
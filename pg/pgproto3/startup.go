@@ -0,0 +1,79 @@
+package pgproto3
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// StartupMessage is the first message a frontend sends once it has picked a
+// protocol version, carrying connection parameters such as "user" and
+// "database". Unlike every other message in this package, it has no leading
+// type byte.
+type StartupMessage struct {
+	ProtocolVersion uint32
+	Parameters      map[string]string
+}
+
+func (dst *StartupMessage) Decode(src []byte) error {
+	if len(src) < 4 {
+		return fmt.Errorf("startup message too short")
+	}
+
+	dst.ProtocolVersion = binary.BigEndian.Uint32(src)
+	dst.Parameters = make(map[string]string)
+
+	rp := 4
+	for rp < len(src) && src[rp] != 0 {
+		key, n, err := readCString(src[rp:])
+		if err != nil {
+			return fmt.Errorf("invalid startup parameter name: %w", err)
+		}
+		rp += n
+
+		value, n, err := readCString(src[rp:])
+		if err != nil {
+			return fmt.Errorf("invalid startup parameter value: %w", err)
+		}
+		rp += n
+
+		dst.Parameters[key] = value
+	}
+
+	return nil
+}
+
+func (src *StartupMessage) Encode(dst []byte) []byte {
+	sp := len(dst)
+	dst = appendInt32(dst, -1)
+
+	dst = appendUint32(dst, src.ProtocolVersion)
+	for k, v := range src.Parameters {
+		dst = appendCString(dst, k)
+		dst = appendCString(dst, v)
+	}
+	dst = append(dst, 0)
+
+	binary.BigEndian.PutUint32(dst[sp:], uint32(len(dst[sp:])))
+
+	return dst
+}
+
+// SSLRequest is sent by a frontend in place of a StartupMessage to ask
+// whether the server is willing to negotiate a TLS connection.
+type SSLRequest struct{}
+
+func (dst *SSLRequest) Decode(src []byte) error {
+	if len(src) != 4 {
+		return fmt.Errorf("invalid SSLRequest length: %d", len(src))
+	}
+	if code := binary.BigEndian.Uint32(src); code != sslRequestCode {
+		return fmt.Errorf("invalid SSLRequest code: %d", code)
+	}
+	return nil
+}
+
+func (src *SSLRequest) Encode(dst []byte) []byte {
+	dst = appendInt32(dst, 8)
+	dst = appendUint32(dst, sslRequestCode)
+	return dst
+}
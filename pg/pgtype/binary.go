@@ -0,0 +1,255 @@
+package pgtype
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pgEpoch is the reference instant PostgreSQL's binary timestamp formats
+// count from.
+var pgEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func encodeBinary(oid uint32, v any) ([]byte, error) {
+	if IsArray(oid) {
+		values, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("pgtype: cannot encode %T as an array", v)
+		}
+		return encodeArrayBinary(arrayElementOID[oid], values)
+	}
+
+	switch oid {
+	case BoolOID:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("pgtype: cannot encode %T as bool", v)
+		}
+		if b {
+			return []byte{1}, nil
+		}
+		return []byte{0}, nil
+
+	case Int2OID:
+		n, err := toInt64(v)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 2)
+		binary.BigEndian.PutUint16(buf, uint16(int16(n)))
+		return buf, nil
+
+	case Int4OID:
+		n, err := toInt64(v)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 4)
+		binary.BigEndian.PutUint32(buf, uint32(int32(n)))
+		return buf, nil
+
+	case Int8OID:
+		n, err := toInt64(v)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, uint64(n))
+		return buf, nil
+
+	case Float4OID:
+		f, ok := v.(float32)
+		if !ok {
+			return nil, fmt.Errorf("pgtype: cannot encode %T as float4", v)
+		}
+		buf := make([]byte, 4)
+		binary.BigEndian.PutUint32(buf, math.Float32bits(f))
+		return buf, nil
+
+	case Float8OID:
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("pgtype: cannot encode %T as float8", v)
+		}
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, math.Float64bits(f))
+		return buf, nil
+
+	case TextOID:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("pgtype: cannot encode %T as text", v)
+		}
+		return []byte(s), nil
+
+	case ByteaOID:
+		b, ok := v.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("pgtype: cannot encode %T as bytea", v)
+		}
+		return b, nil
+
+	case NumericOID:
+		s, err := numericString(v)
+		if err != nil {
+			return nil, err
+		}
+		return encodeNumericBinary(s)
+
+	case TimestampTZOID:
+		t, ok := v.(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("pgtype: cannot encode %T as timestamptz", v)
+		}
+		micros := t.UTC().Sub(pgEpoch).Microseconds()
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, uint64(micros))
+		return buf, nil
+
+	case UUIDOID:
+		return encodeUUIDBinary(v)
+
+	case JSONOID:
+		s, err := jsonString(v)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(s), nil
+
+	case JSONBOID:
+		s, err := jsonString(v)
+		if err != nil {
+			return nil, err
+		}
+		// jsonb's binary format is the json text prefixed by a one-byte
+		// version number, currently always 1.
+		return append([]byte{1}, s...), nil
+
+	default:
+		return nil, fmt.Errorf("pgtype: unsupported type OID: %d", oid)
+	}
+}
+
+func encodeUUIDBinary(v any) ([]byte, error) {
+	switch u := v.(type) {
+	case [16]byte:
+		b := make([]byte, 16)
+		copy(b, u[:])
+		return b, nil
+	case string:
+		hexStr := strings.ReplaceAll(u, "-", "")
+		b, err := hex.DecodeString(hexStr)
+		if err != nil || len(b) != 16 {
+			return nil, fmt.Errorf("pgtype: invalid uuid %q", u)
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("pgtype: cannot encode %T as uuid", v)
+	}
+}
+
+func encodeArrayBinary(elemOID uint32, values []any) ([]byte, error) {
+	hasNull := int32(0)
+	for _, v := range values {
+		if v == nil {
+			hasNull = 1
+			break
+		}
+	}
+
+	buf := make([]byte, 0, 20)
+	buf = appendInt32(buf, 1) // ndim: this package only supports 1-D arrays
+	buf = appendInt32(buf, hasNull)
+	buf = appendUint32(buf, elemOID)
+	buf = appendInt32(buf, int32(len(values)))
+	buf = appendInt32(buf, 1) // lower bound
+
+	for _, v := range values {
+		if v == nil {
+			buf = appendInt32(buf, -1)
+			continue
+		}
+		eb, err := encodeBinary(elemOID, v)
+		if err != nil {
+			return nil, err
+		}
+		buf = appendInt32(buf, int32(len(eb)))
+		buf = append(buf, eb...)
+	}
+
+	return buf, nil
+}
+
+// encodeNumericBinary packs a decimal string into PostgreSQL's base-10000
+// numeric wire format: ndigits, weight, sign, dscale, then the digits
+// themselves, most significant first.
+func encodeNumericBinary(s string) ([]byte, error) {
+	sign := uint16(0x0000)
+	if strings.HasPrefix(s, "-") {
+		sign = 0x4000
+		s = s[1:]
+	} else if strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" {
+		intPart = "0"
+	}
+	for _, r := range intPart + fracPart {
+		if r < '0' || r > '9' {
+			return nil, fmt.Errorf("pgtype: invalid numeric value %q", s)
+		}
+	}
+	dscale := uint16(len(fracPart))
+
+	intPart = strings.Repeat("0", (4-len(intPart)%4)%4) + intPart
+	fracPart = fracPart + strings.Repeat("0", (4-len(fracPart)%4)%4)
+	weight := int16(len(intPart)/4 - 1)
+
+	digitsStr := intPart + fracPart
+	digits := make([]int16, 0, len(digitsStr)/4)
+	for i := 0; i < len(digitsStr); i += 4 {
+		d, err := strconv.Atoi(digitsStr[i : i+4])
+		if err != nil {
+			return nil, fmt.Errorf("pgtype: invalid numeric value %q: %w", s, err)
+		}
+		digits = append(digits, int16(d))
+	}
+	for len(digits) > 0 && digits[len(digits)-1] == 0 {
+		digits = digits[:len(digits)-1]
+	}
+	if len(digits) == 0 {
+		weight = 0
+	}
+
+	buf := make([]byte, 0, 8+2*len(digits))
+	buf = appendUint16(buf, uint16(len(digits)))
+	buf = appendInt16(buf, weight)
+	buf = appendUint16(buf, sign)
+	buf = appendUint16(buf, dscale)
+	for _, d := range digits {
+		buf = appendInt16(buf, d)
+	}
+	return buf, nil
+}
+
+func appendInt16(dst []byte, n int16) []byte {
+	return binary.BigEndian.AppendUint16(dst, uint16(n))
+}
+
+func appendUint16(dst []byte, n uint16) []byte {
+	return binary.BigEndian.AppendUint16(dst, n)
+}
+
+func appendInt32(dst []byte, n int32) []byte {
+	return binary.BigEndian.AppendUint32(dst, uint32(n))
+}
+
+func appendUint32(dst []byte, n uint32) []byte {
+	return binary.BigEndian.AppendUint32(dst, n)
+}
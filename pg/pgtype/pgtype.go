@@ -0,0 +1,64 @@
+// Package pgtype maps Go values onto the PostgreSQL wire representations
+// for a handful of common pg_type OIDs, in both text and binary format.
+package pgtype
+
+import "fmt"
+
+// Well-known type OIDs, from PostgreSQL's pg_type catalog.
+const (
+	BoolOID        uint32 = 16
+	ByteaOID       uint32 = 17
+	Int8OID        uint32 = 20
+	Int2OID        uint32 = 21
+	Int4OID        uint32 = 23
+	TextOID        uint32 = 25
+	JSONOID        uint32 = 114
+	Float4OID      uint32 = 700
+	Float8OID      uint32 = 701
+	TimestampTZOID uint32 = 1184
+	NumericOID     uint32 = 1700
+	UUIDOID        uint32 = 2950
+	JSONBOID       uint32 = 3802
+
+	Int2ArrayOID   uint32 = 1005
+	Int4ArrayOID   uint32 = 1007
+	TextArrayOID   uint32 = 1009
+	Float4ArrayOID uint32 = 1021
+	Float8ArrayOID uint32 = 1022
+	Int8ArrayOID   uint32 = 1016
+)
+
+// arrayElementOID maps an array OID to the OID of its elements.
+var arrayElementOID = map[uint32]uint32{
+	Int2ArrayOID:   Int2OID,
+	Int4ArrayOID:   Int4OID,
+	Int8ArrayOID:   Int8OID,
+	TextArrayOID:   TextOID,
+	Float4ArrayOID: Float4OID,
+	Float8ArrayOID: Float8OID,
+}
+
+// IsArray reports whether oid is one of the array OIDs this package knows
+// how to encode.
+func IsArray(oid uint32) bool {
+	_, ok := arrayElementOID[oid]
+	return ok
+}
+
+// Encode converts v into the wire representation for oid in the requested
+// format code (0 text, 1 binary). A nil v always encodes as SQL NULL,
+// represented as a nil byte slice.
+func Encode(oid uint32, format int16, v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch format {
+	case 0:
+		b, err := encodeText(oid, v)
+		return []byte(b), err
+	case 1:
+		return encodeBinary(oid, v)
+	default:
+		return nil, fmt.Errorf("pgtype: unsupported format code: %d", format)
+	}
+}
@@ -0,0 +1,184 @@
+package pgtype
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func encodeText(oid uint32, v any) (string, error) {
+	if IsArray(oid) {
+		values, ok := v.([]any)
+		if !ok {
+			return "", fmt.Errorf("pgtype: cannot encode %T as an array", v)
+		}
+		return encodeArrayText(arrayElementOID[oid], values)
+	}
+
+	switch oid {
+	case BoolOID:
+		b, ok := v.(bool)
+		if !ok {
+			return "", fmt.Errorf("pgtype: cannot encode %T as bool", v)
+		}
+		if b {
+			return "t", nil
+		}
+		return "f", nil
+
+	case Int2OID, Int4OID, Int8OID:
+		n, err := toInt64(v)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(n, 10), nil
+
+	case Float4OID:
+		f, ok := v.(float32)
+		if !ok {
+			return "", fmt.Errorf("pgtype: cannot encode %T as float4", v)
+		}
+		return strconv.FormatFloat(float64(f), 'g', -1, 32), nil
+
+	case Float8OID:
+		f, ok := v.(float64)
+		if !ok {
+			return "", fmt.Errorf("pgtype: cannot encode %T as float8", v)
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+
+	case TextOID:
+		s, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("pgtype: cannot encode %T as text", v)
+		}
+		return s, nil
+
+	case ByteaOID:
+		b, ok := v.([]byte)
+		if !ok {
+			return "", fmt.Errorf("pgtype: cannot encode %T as bytea", v)
+		}
+		return `\x` + hex.EncodeToString(b), nil
+
+	case NumericOID:
+		return numericString(v)
+
+	case TimestampTZOID:
+		t, ok := v.(time.Time)
+		if !ok {
+			return "", fmt.Errorf("pgtype: cannot encode %T as timestamptz", v)
+		}
+		return t.UTC().Format("2006-01-02 15:04:05.999999Z07:00"), nil
+
+	case UUIDOID:
+		s, err := uuidString(v)
+		if err != nil {
+			return "", err
+		}
+		return s, nil
+
+	case JSONOID, JSONBOID:
+		return jsonString(v)
+
+	default:
+		return "", fmt.Errorf("pgtype: unsupported type OID: %d", oid)
+	}
+}
+
+func encodeArrayText(elemOID uint32, values []any) (string, error) {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		if v == nil {
+			parts[i] = "NULL"
+			continue
+		}
+		s, err := encodeText(elemOID, v)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = quoteArrayElement(s)
+	}
+	return "{" + strings.Join(parts, ",") + "}", nil
+}
+
+func quoteArrayElement(s string) string {
+	needsQuote := s == ""
+	for _, r := range s {
+		switch r {
+		case ',', '{', '}', ' ', '"', '\\':
+			needsQuote = true
+		}
+	}
+	if !needsQuote {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func toInt64(v any) (int64, error) {
+	switch n := v.(type) {
+	case int16:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case int:
+		return int64(n), nil
+	default:
+		return 0, fmt.Errorf("pgtype: cannot encode %T as an integer", v)
+	}
+}
+
+// numericString returns the decimal text representation of v, accepting
+// either a pre-formatted string or a float64.
+func numericString(v any) (string, error) {
+	switch n := v.(type) {
+	case string:
+		return n, nil
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("pgtype: cannot encode %T as numeric", v)
+	}
+}
+
+func uuidString(v any) (string, error) {
+	switch u := v.(type) {
+	case string:
+		return u, nil
+	case [16]byte:
+		return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16]), nil
+	default:
+		return "", fmt.Errorf("pgtype: cannot encode %T as uuid", v)
+	}
+}
+
+func jsonString(v any) (string, error) {
+	switch j := v.(type) {
+	case string:
+		return j, nil
+	case []byte:
+		return string(j), nil
+	default:
+		b, err := json.Marshal(j)
+		if err != nil {
+			return "", fmt.Errorf("pgtype: cannot encode %T as json: %w", v, err)
+		}
+		return string(b), nil
+	}
+}
@@ -1,268 +1,694 @@
 package pg
 
 import (
-	"bytes"
-	"encoding/binary"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
-	"io"
+	"net"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
-)
-
-const (
-	StartupMessage = 196608
-	SSLRequest     = 80877103
-)
 
-var (
-	TypeLen = map[string]int16{
-		"bool": 1,
-		"text": -1,
-	}
-	Disconnect = fmt.Errorf("Client disconnected")
+	"github.com/jerluc/pgany/pg/pgproto3"
+	"github.com/jerluc/pgany/pg/pgtype"
 )
 
-func WriteMessage(conn io.Writer, parts ...any) (int, error) {
-	contentsBuf := bytes.NewBuffer([]byte{})
-	for i, part := range parts {
-		if i == 0 {
-			continue
-		}
-		binary.Write(contentsBuf, binary.BigEndian, part)
-	}
+type contextKey string
 
-	msgType := parts[0].(byte)
-	contents := contentsBuf.Bytes()
-	msgBuf := bytes.NewBuffer([]byte{})
-	msgBuf.WriteByte(msgType)
-	binary.Write(msgBuf, binary.BigEndian, int32(len(contents)+4))
-	msgBuf.Write(contents)
+// tlsConnectionStateKey is the context.Context key under which the
+// negotiated TLS connection state is stored, once a client has completed a
+// TLS handshake.
+const tlsConnectionStateKey contextKey = "tlsConnectionState"
 
-	return conn.Write(msgBuf.Bytes())
+// TLSConnectionStateFromContext returns the TLS connection state negotiated
+// for a connection, if the client connected over TLS.
+func TLSConnectionStateFromContext(ctx context.Context) (tls.ConnectionState, bool) {
+	state, ok := ctx.Value(tlsConnectionStateKey).(tls.ConnectionState)
+	return state, ok
 }
 
-func AuthenticationOk(conn io.Writer) (int, error) {
-	return WriteMessage(conn, byte('R'), int32(0))
-}
+// serverCertificateKey is the context.Context key under which the DER bytes
+// of the certificate offered to a TLS client are stored, for deriving SCRAM
+// channel binding data.
+const serverCertificateKey contextKey = "serverCertificate"
 
-func ReadyForQuery(conn io.Writer) (int, error) {
-	return WriteMessage(conn, byte('Z'), byte('I'))
+// ServerCertificateFromContext returns the DER bytes of the certificate this
+// server offered the client during the TLS handshake, if any.
+func ServerCertificateFromContext(ctx context.Context) ([]byte, bool) {
+	cert, ok := ctx.Value(serverCertificateKey).([]byte)
+	return cert, ok
 }
 
-func RowDescription(conn io.Writer, data []map[string]any) (int, error) {
-	numFields := len(data[0])
-	var parts []any = []any{
-		byte('T'), int16(numFields)}
-	for k := range data[0] {
-		// Field name
-		name := bytes.NewBufferString(k).Bytes()
-		for _, b := range name {
-			parts = append(parts,
-				b,
-			)
-		}
-		parts = append(parts, byte(0))
-
-		parts = append(parts,
-			// Table object ID
-			int32(0),
-			// Attr number
-			int16(0),
-			// Data type object ID
-			int32(0),
-			// Data type size (pg_type.typlen)
-			int16(-1),
-			// Type modifier (pg_attribute.atttypmod
-			int32(0),
-			// Format code (0 or 1 for text or binary)
-			int16(0),
-		)
-	}
-	return WriteMessage(conn, parts...)
-}
+// PGProtocolConfig configures a PGProtocol.
+type PGProtocolConfig struct {
+	// TLSConfig, when non-nil, is offered to the client if it sends an
+	// SSLRequest during startup.
+	TLSConfig *tls.Config
 
-func DataRow(conn io.Writer, row map[string]any) (int, error) {
-	numFields := len(row)
-	var parts []any = []any{
-		byte('D'), int16(numFields)}
-	for _, v := range row {
-		// Value length
-		vb := bytes.NewBuffer([]byte{})
-		if s, isString := v.(string); isString {
-			sb := bytes.NewBufferString(s)
-			vb.ReadFrom(sb)
-			vb.WriteByte(0)
-		} else {
-			binary.Write(vb, binary.BigEndian, v)
-		}
-		parts = append(parts, int32(vb.Len()))
-		for _, b := range vb.Bytes() {
-			parts = append(parts,
-				b,
-			)
-		}
-	}
-	return WriteMessage(conn, parts...)
-}
+	// RequireTLS rejects the connection unless the client negotiates TLS.
+	RequireTLS bool
 
-func CommandComplete(conn io.Writer, tag string) (int, error) {
-	var parts []any = []any{byte('C')}
-	tagb := bytes.NewBufferString(tag).Bytes()
-	for _, b := range tagb {
-		parts = append(parts,
-			b,
-		)
-	}
-	parts = append(parts, byte(0))
-	return WriteMessage(conn, parts...)
+	// Authenticator, when non-nil, is run after the StartupMessage to
+	// authenticate the connection. When nil, any client is accepted
+	// (trust authentication).
+	Authenticator Authenticator
+
+	// QueryHandler executes client queries. When nil, the protocol
+	// responds to every query with a fixed placeholder result set.
+	QueryHandler QueryHandler
 }
 
+// PGProtocol drives the PostgreSQL wire protocol for a single client
+// connection: the startup handshake, authentication, and the query loop.
 type PGProtocol struct {
-	clientConn io.ReadWriteCloser
+	clientConn net.Conn
 	log        log.Ext1FieldLogger
+	backend    *pgproto3.Backend
+	ctx        context.Context
+
+	tlsConfig     *tls.Config
+	requireTLS    bool
+	authenticator Authenticator
+	queryHandler  QueryHandler
+
+	startupParams map[string]string
+	session       *Session
 }
 
-func (pg *PGProtocol) Write(b []byte) (int, error) {
-	pg.log.Tracef("[WRITE] %v\n", b)
-	n, err := pg.clientConn.Write(b)
-	return n, err
+func NewPGProtocol(clientConn net.Conn, logger log.Ext1FieldLogger, cfg PGProtocolConfig) *PGProtocol {
+	return &PGProtocol{
+		clientConn:    clientConn,
+		log:           logger,
+		backend:       pgproto3.NewBackend(clientConn, clientConn),
+		ctx:           context.Background(),
+		tlsConfig:     cfg.TLSConfig,
+		requireTLS:    cfg.RequireTLS,
+		authenticator: cfg.Authenticator,
+		queryHandler:  cfg.QueryHandler,
+	}
 }
 
+// Startup reads one startup-phase message. It returns true once the
+// frontend has sent a StartupMessage and the connection is ready for
+// authentication; callers should keep calling Startup until it does.
 func (pg *PGProtocol) Startup() (bool, error) {
-	msgLength, err := pg.ReadInt32()
-	if err != nil {
-		return false, err
-	}
-	contentLength := int64(msgLength - 4)
-	buf := bytes.NewBuffer([]byte{})
-	n, err := io.CopyN(buf, pg.clientConn, contentLength)
+	msg, err := pg.backend.ReceiveStartupMessage()
 	if err != nil {
 		return false, err
 	}
-	if n != contentLength {
-		return false, fmt.Errorf("Buffer underflow, only read %d bytes (expected %d)", n, contentLength)
-	}
-	var protocolVersion uint32
-	err = binary.Read(buf, binary.BigEndian, &protocolVersion)
-	if err != nil {
-		return false, err
+
+	switch m := msg.(type) {
+	case *pgproto3.SSLRequest:
+		return pg.handleSSLRequest()
+	case *pgproto3.StartupMessage:
+		pg.startupParams = m.Parameters
+		if pg.requireTLS {
+			if _, ok := TLSConnectionStateFromContext(pg.ctx); !ok {
+				if err := pg.backend.Send(&pgproto3.ErrorResponse{
+					Severity: "FATAL",
+					Code:     "08P01",
+					Message:  "SSL/TLS required",
+				}); err != nil {
+					return false, err
+				}
+				return false, fmt.Errorf("Client did not negotiate required TLS")
+			}
+		}
+		// TODO: Authentication loop?
+		return true, nil
+	default:
+		return false, fmt.Errorf("Unexpected startup message: %T", msg)
 	}
+}
 
-	if protocolVersion == SSLRequest {
-		// TODO: Add support for SSL connections
-		if err := binary.Write(pg, binary.BigEndian, byte('N')); err != nil {
+// handleSSLRequest responds to a client's SSLRequest. If pg.tlsConfig is set
+// it accepts the negotiation and performs the TLS handshake in place,
+// swapping clientConn and backend over to the encrypted connection.
+// Otherwise it declines, as real Postgres does when built without SSL
+// support.
+func (pg *PGProtocol) handleSSLRequest() (bool, error) {
+	if pg.tlsConfig == nil {
+		if _, err := pg.clientConn.Write([]byte{'N'}); err != nil {
 			return false, err
 		}
 		// We still need to wait for the StartupMessage before "ready"
 		return false, nil
-	} else if protocolVersion == StartupMessage {
-		for {
-			_, err := buf.ReadString(0)
-			if err == io.EOF {
-				break
+	}
+
+	if _, err := pg.clientConn.Write([]byte{'S'}); err != nil {
+		return false, err
+	}
+
+	tlsConn := tls.Server(pg.clientConn, pg.tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		return false, fmt.Errorf("TLS handshake failed: %w", err)
+	}
+
+	pg.clientConn = tlsConn
+	pg.backend = pgproto3.NewBackend(tlsConn, tlsConn)
+	pg.ctx = context.WithValue(pg.ctx, tlsConnectionStateKey, tlsConn.ConnectionState())
+	if len(pg.tlsConfig.Certificates) > 0 && len(pg.tlsConfig.Certificates[0].Certificate) > 0 {
+		pg.ctx = context.WithValue(pg.ctx, serverCertificateKey, pg.tlsConfig.Certificates[0].Certificate[0])
+	}
+
+	// We still need to wait for the StartupMessage before "ready"
+	return false, nil
+}
+
+func (pg *PGProtocol) Loop() error {
+	for {
+		ready, err := pg.Startup()
+		if err != nil {
+			return err
+		}
+		if ready {
+			break
+		}
+	}
+
+	user := pg.startupParams["user"]
+	if pg.authenticator != nil {
+		if err := pg.authenticator.Authenticate(pg.ctx, pg.backend, user); err != nil {
+			if sendErr := pg.backend.Send(&pgproto3.ErrorResponse{
+				Severity: "FATAL",
+				Code:     "28P01",
+				Message:  fmt.Sprintf("password authentication failed for user %q", user),
+			}); sendErr != nil {
+				return sendErr
 			}
-			if err != nil {
-				return false, err
+			return err
+		}
+	} else if err := pg.backend.Send(&pgproto3.AuthenticationOk{}); err != nil {
+		return err
+	}
+
+	pg.session = NewSession(user)
+
+	if err := pg.backend.Send(&pgproto3.ReadyForQuery{TxStatus: byte(pg.session.TxState())}); err != nil {
+		return err
+	}
+
+	// inErrorState mirrors real Postgres: once an extended query message
+	// fails, every message up to the next Sync is skipped; that Sync clears
+	// the error state and is answered with ReadyForQuery.
+	inErrorState := false
+
+	for {
+		msg, err := pg.backend.Receive()
+		if err != nil {
+			return err
+		}
+
+		if inErrorState {
+			switch msg.(type) {
+			case *pgproto3.Terminate:
+				return nil
+			case *pgproto3.Sync:
+				inErrorState = false
+				if err := pg.backend.Send(&pgproto3.ReadyForQuery{TxStatus: byte(pg.session.TxState())}); err != nil {
+					return err
+				}
 			}
-			// TODO: Authentication loop?
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *pgproto3.Terminate:
+			return nil
+
+		case *pgproto3.Query:
+			if err := pg.handleQuery(m); err != nil {
+				pg.session.FailTx()
+				if sendErr := pg.sendError(err); sendErr != nil {
+					return sendErr
+				}
+			}
+			if err := pg.backend.Send(&pgproto3.ReadyForQuery{TxStatus: byte(pg.session.TxState())}); err != nil {
+				return err
+			}
+
+		case *pgproto3.Parse:
+			if err := pg.handleParse(m); err != nil {
+				pg.session.FailTx()
+				if sendErr := pg.sendError(err); sendErr != nil {
+					return sendErr
+				}
+				inErrorState = true
+			}
+
+		case *pgproto3.Bind:
+			if err := pg.handleBind(m); err != nil {
+				pg.session.FailTx()
+				if sendErr := pg.sendError(err); sendErr != nil {
+					return sendErr
+				}
+				inErrorState = true
+			}
+
+		case *pgproto3.Describe:
+			if err := pg.handleDescribe(m); err != nil {
+				pg.session.FailTx()
+				if sendErr := pg.sendError(err); sendErr != nil {
+					return sendErr
+				}
+				inErrorState = true
+			}
+
+		case *pgproto3.Execute:
+			if err := pg.handleExecute(m); err != nil {
+				pg.session.FailTx()
+				if sendErr := pg.sendError(err); sendErr != nil {
+					return sendErr
+				}
+				inErrorState = true
+			}
+
+		case *pgproto3.Close:
+			if err := pg.handleClose(m); err != nil {
+				pg.session.FailTx()
+				if sendErr := pg.sendError(err); sendErr != nil {
+					return sendErr
+				}
+				inErrorState = true
+			}
+
+		case *pgproto3.Sync:
+			if err := pg.backend.Send(&pgproto3.ReadyForQuery{TxStatus: byte(pg.session.TxState())}); err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("Unexpected message: %T", msg)
 		}
-		return true, nil
 	}
-	return false, fmt.Errorf("Unknown protocol version: %d", protocolVersion)
 }
 
-func (pg *PGProtocol) ReadQuery() (string, error) {
-	var msgType byte
-	err := binary.Read(pg.clientConn, binary.BigEndian, &msgType)
-	if err != nil {
-		return "", err
+// sendError reports err to the client as an ErrorResponse, without closing
+// the connection. If err wraps a *pg.Error, its fields (including SQLSTATE)
+// are surfaced verbatim so clients can key retry logic off of codes like
+// serialization_failure; any other error is reported as a generic internal
+// error.
+func (pg *PGProtocol) sendError(err error) error {
+	return pg.backend.Send(toErrorResponse(err))
+}
+
+func toErrorResponse(err error) *pgproto3.ErrorResponse {
+	var pgErr *Error
+	if errors.As(err, &pgErr) {
+		severity := pgErr.Severity
+		if severity == "" {
+			severity = "ERROR"
+		}
+		return &pgproto3.ErrorResponse{
+			Severity:       severity,
+			Code:           pgErr.Code,
+			Message:        pgErr.Message,
+			Detail:         pgErr.Detail,
+			Hint:           pgErr.Hint,
+			Position:       pgErr.Position,
+			Where:          pgErr.Where,
+			SchemaName:     pgErr.SchemaName,
+			TableName:      pgErr.TableName,
+			ColumnName:     pgErr.ColumnName,
+			DataTypeName:   pgErr.DataTypeName,
+			ConstraintName: pgErr.ConstraintName,
+			File:           pgErr.File,
+			Line:           pgErr.Line,
+			Routine:        pgErr.Routine,
+		}
+	}
+	return &pgproto3.ErrorResponse{
+		Severity: "ERROR",
+		Code:     SQLStateInternalError,
+		Message:  err.Error(),
+	}
+}
+
+// TODO: Wire up a call site for NoticeResponse once something produces
+// pg.Notice values, e.g. a QueryHandler surfacing non-fatal warnings.
+func (pg *PGProtocol) sendNotice(n *Notice) error {
+	e := Error(*n)
+	resp := toErrorResponse(&e)
+	return pg.backend.Send(&pgproto3.NoticeResponse{
+		Severity:       resp.Severity,
+		Code:           resp.Code,
+		Message:        resp.Message,
+		Detail:         resp.Detail,
+		Hint:           resp.Hint,
+		Position:       resp.Position,
+		Where:          resp.Where,
+		SchemaName:     resp.SchemaName,
+		TableName:      resp.TableName,
+		ColumnName:     resp.ColumnName,
+		DataTypeName:   resp.DataTypeName,
+		ConstraintName: resp.ConstraintName,
+		File:           resp.File,
+		Line:           resp.Line,
+		Routine:        resp.Routine,
+	})
+}
+
+// The placeholder result set served when no QueryHandler is configured.
+var (
+	fakeResultFields = []pgproto3.FieldDescription{
+		{Name: "a", DataTypeSize: -1},
+		{Name: "b", DataTypeSize: -1},
+		{Name: "c", DataTypeSize: -1},
 	}
-	if msgType == 'X' {
-		return "", Disconnect
+	fakeResultRows = [][]string{
+		{"1", "B1", "C1"},
+		{"2", "B2", "C2"},
 	}
-	if msgType != 'Q' {
-		return "", fmt.Errorf("Expected 'Q', but got '%s'", string(msgType))
+)
+
+// fieldDescriptionsFor builds the RowDescription fields for columns. When
+// resultFormats has a single entry, it applies to every column, matching the
+// Bind message's "apply to all" shorthand; otherwise each column uses its
+// own entry if present, falling back to the column's declared format.
+func fieldDescriptionsFor(columns []Column, resultFormats []int16) []pgproto3.FieldDescription {
+	fields := make([]pgproto3.FieldDescription, len(columns))
+	for i, c := range columns {
+		fields[i] = pgproto3.FieldDescription{
+			Name:         c.Name,
+			DataTypeOID:  c.OID,
+			DataTypeSize: c.TypeSize,
+			TypeModifier: c.TypeModifier,
+			Format:       resultFormat(c, i, resultFormats),
+		}
 	}
-	msgLength, err := pg.ReadInt32()
-	if err != nil {
-		return "", err
+	return fields
+}
+
+func resultFormat(c Column, i int, resultFormats []int16) int16 {
+	switch {
+	case len(resultFormats) == 1:
+		return resultFormats[0]
+	case i < len(resultFormats):
+		return resultFormats[i]
+	default:
+		return c.Format
 	}
-	contentLength := int64(msgLength - 4)
-	buf := bytes.NewBuffer([]byte{})
-	n, err := io.CopyN(buf, pg.clientConn, contentLength)
-	if n != contentLength {
-		return "", fmt.Errorf("Buffer underflow, only read %d bytes (expected %d)", n, contentLength)
+}
+
+// bindParams builds the Param slice a QueryHandler sees for a bound portal,
+// pairing each parameter's wire bytes with the OID its Parse declared (0 if
+// Parse didn't specify one) and its effective format code. When
+// paramFormats has a single entry, it applies to every parameter, matching
+// the Bind message's "apply to all" shorthand; an empty paramFormats means
+// every parameter is text, per the protocol's default.
+func bindParams(portal *Portal) []Param {
+	oids := portal.Statement.ParameterOIDs
+	params := make([]Param, len(portal.Parameters))
+	for i, v := range portal.Parameters {
+		var oid uint32
+		if i < len(oids) {
+			oid = oids[i]
+		}
+		params[i] = Param{OID: oid, Format: paramFormat(i, portal.ParameterFormats), Value: v}
 	}
-	return buf.String(), nil
+	return params
 }
 
-func (pg *PGProtocol) ReadInt32() (int32, error) {
-	var v int32
-	if err := binary.Read(pg.clientConn, binary.BigEndian, &v); err != nil {
-		return 0, err
+func paramFormat(i int, paramFormats []int16) int16 {
+	switch {
+	case len(paramFormats) == 1:
+		return paramFormats[0]
+	case i < len(paramFormats):
+		return paramFormats[i]
+	default:
+		return 0
 	}
-	return v, nil
 }
 
-func (pg *PGProtocol) Loop() error {
-	for {
-		ready, err := pg.Startup()
+func encodeRow(columns []Column, values []any, resultFormats []int16) (*pgproto3.DataRow, error) {
+	out := make([][]byte, len(values))
+	for i, v := range values {
+		b, err := pgtype.Encode(columns[i].OID, resultFormat(columns[i], i, resultFormats), v)
 		if err != nil {
+			return nil, err
+		}
+		out[i] = b
+	}
+	return &pgproto3.DataRow{Values: out}, nil
+}
+
+// transactionControlTag recognizes the simple-query forms of BEGIN, COMMIT,
+// and ROLLBACK (including their synonyms START TRANSACTION and END) so the
+// session's transaction state can be tracked without a real SQL parser.
+func transactionControlTag(sql string) (string, bool) {
+	fields := strings.Fields(strings.TrimSuffix(strings.TrimSpace(sql), ";"))
+	if len(fields) == 0 {
+		return "", false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "BEGIN", "START":
+		return "BEGIN", true
+	case "COMMIT", "END":
+		return "COMMIT", true
+	case "ROLLBACK":
+		return "ROLLBACK", true
+	default:
+		return "", false
+	}
+}
+
+func (pg *PGProtocol) handleQuery(q *pgproto3.Query) error {
+	queryLogger := pg.log.WithField("query", q.String)
+	queryLogger.Debug("Received query")
+
+	if tag, ok := transactionControlTag(q.String); ok {
+		switch tag {
+		case "BEGIN":
+			pg.session.BeginTx()
+		case "COMMIT", "ROLLBACK":
+			pg.session.EndTx()
+		}
+		return pg.backend.Send(&pgproto3.CommandComplete{CommandTag: tag})
+	}
+
+	if pg.queryHandler == nil {
+		if err := pg.backend.Send(&pgproto3.RowDescription{Fields: fakeResultFields}); err != nil {
 			return err
 		}
-		if ready {
-			break
+		for _, row := range fakeResultRows {
+			values := make([][]byte, len(row))
+			for i, v := range row {
+				values[i] = []byte(v)
+			}
+			if err := pg.backend.Send(&pgproto3.DataRow{Values: values}); err != nil {
+				return err
+			}
 		}
+		return pg.backend.Send(&pgproto3.CommandComplete{CommandTag: fmt.Sprintf("SELECT %d", len(fakeResultRows))})
 	}
 
-	_, err := AuthenticationOk(pg)
+	rows, err := pg.queryHandler.Query(pg.ctx, pg.session, q.String, nil)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
 
-	for {
-		_, err = ReadyForQuery(pg)
+	columns := rows.Columns()
+	// The simple query protocol has no way to request binary results, so
+	// every column is sent in text format regardless of its preferred
+	// Format: pass the "apply to all" shorthand forcing format 0 rather
+	// than nil, which would fall back to each column's own preference.
+	textFormats := []int16{0}
+	if err := pg.backend.Send(&pgproto3.RowDescription{Fields: fieldDescriptionsFor(columns, textFormats)}); err != nil {
+		return err
+	}
+
+	var n int
+	for rows.Next() {
+		dataRow, err := encodeRow(columns, rows.Values(), textFormats)
 		if err != nil {
 			return err
 		}
+		if err := pg.backend.Send(dataRow); err != nil {
+			return err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
 
-		q, err := pg.ReadQuery()
-		if err == Disconnect {
-			break
+	return pg.backend.Send(&pgproto3.CommandComplete{CommandTag: fmt.Sprintf("SELECT %d", n)})
+}
+
+func (pg *PGProtocol) handleParse(m *pgproto3.Parse) error {
+	pg.session.AddPreparedStatement(&PreparedStatement{
+		Name:          m.Name,
+		Query:         m.Query,
+		ParameterOIDs: m.ParameterOIDs,
+	})
+	return pg.backend.Send(&pgproto3.ParseComplete{})
+}
+
+func (pg *PGProtocol) handleBind(m *pgproto3.Bind) error {
+	stmt, ok := pg.session.PreparedStatement(m.PreparedStatement)
+	if !ok {
+		return fmt.Errorf("prepared statement %q does not exist", m.PreparedStatement)
+	}
+
+	// m.Parameters points into the backend's reused read buffer, so copy
+	// anything we retain past this message.
+	params := make([][]byte, len(m.Parameters))
+	for i, p := range m.Parameters {
+		if p != nil {
+			params[i] = append([]byte(nil), p...)
 		}
+	}
+
+	portal := &Portal{
+		Name:             m.DestinationPortal,
+		Statement:        stmt,
+		Parameters:       params,
+		ParameterFormats: m.ParameterFormatCodes,
+		ResultFormats:    m.ResultFormatCodes,
+	}
+	// If the statement was Described before this Bind, adopt the cursor
+	// that opened rather than running the query a second time.
+	if stmt.rows != nil {
+		portal.rows = stmt.rows
+		stmt.rows = nil
+	}
+	pg.session.AddPortal(portal)
+	return pg.backend.Send(&pgproto3.BindComplete{})
+}
+
+// lazyRows returns the result cursor cached in *cache, opening it against
+// query and params via the configured QueryHandler on first use. Describe
+// and Execute share this so an Execute reads from the cursor a preceding
+// Describe opened instead of running the query again.
+func (pg *PGProtocol) lazyRows(cache *Rows, query string, params []Param) (Rows, error) {
+	if *cache == nil {
+		rows, err := pg.queryHandler.Query(pg.ctx, pg.session, query, params)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		*cache = rows
+	}
+	return *cache, nil
+}
 
-		queryLogger := pg.log.WithField("query", q)
-		queryLogger.Debug("Received query")
-		// TODO: Actually run the query
-		data := []map[string]any{
-			{
-				"a": 1,
-				"b": "B1",
-				"c": "C1",
-			},
-			{
-				"a": 2,
-				"b": "B2",
-				"c": "C2",
-			},
-		}
-		_, err = RowDescription(pg, data)
+func (pg *PGProtocol) handleDescribe(m *pgproto3.Describe) error {
+	switch m.ObjectType {
+	case 'S':
+		stmt, ok := pg.session.PreparedStatement(m.Name)
+		if !ok {
+			return fmt.Errorf("prepared statement %q does not exist", m.Name)
+		}
+		if err := pg.backend.Send(&pgproto3.ParameterDescription{ParameterOIDs: stmt.ParameterOIDs}); err != nil {
+			return err
+		}
+		if pg.queryHandler == nil {
+			return pg.backend.Send(&pgproto3.RowDescription{Fields: fakeResultFields})
+		}
+		// No Bind has happened yet, so there are no parameter values to pass.
+		rows, err := pg.lazyRows(&stmt.rows, stmt.Query, nil)
+		if err != nil {
+			return err
+		}
+		return pg.backend.Send(&pgproto3.RowDescription{Fields: fieldDescriptionsFor(rows.Columns(), nil)})
+	case 'P':
+		portal, ok := pg.session.Portal(m.Name)
+		if !ok {
+			return fmt.Errorf("portal %q does not exist", m.Name)
+		}
+		if pg.queryHandler == nil {
+			return pg.backend.Send(&pgproto3.RowDescription{Fields: fakeResultFields})
+		}
+		rows, err := pg.lazyRows(&portal.rows, portal.Statement.Query, bindParams(portal))
 		if err != nil {
 			return err
 		}
-		for _, row := range data {
-			_, err = DataRow(pg, row)
-			if err != nil {
+		return pg.backend.Send(&pgproto3.RowDescription{Fields: fieldDescriptionsFor(rows.Columns(), portal.ResultFormats)})
+	default:
+		return fmt.Errorf("unknown Describe object type: %q", string(m.ObjectType))
+	}
+}
+
+func (pg *PGProtocol) handleExecute(m *pgproto3.Execute) error {
+	portal, ok := pg.session.Portal(m.Portal)
+	if !ok {
+		return fmt.Errorf("portal %q does not exist", m.Portal)
+	}
+
+	if pg.queryHandler == nil {
+		// No QueryHandler: serve the placeholder rows, whatever the portal's
+		// statement and parameters.
+		rows := fakeResultRows
+		suspended := false
+		if maxRows := int(m.MaxRows); maxRows > 0 && maxRows < len(rows) {
+			rows = rows[:maxRows]
+			suspended = true
+		}
+
+		for _, row := range rows {
+			values := make([][]byte, len(row))
+			for i, v := range row {
+				values[i] = []byte(v)
+			}
+			if err := pg.backend.Send(&pgproto3.DataRow{Values: values}); err != nil {
 				return err
 			}
 		}
-		_, err = CommandComplete(pg, "what")
+
+		if suspended {
+			return pg.backend.Send(&pgproto3.PortalSuspended{})
+		}
+		return pg.backend.Send(&pgproto3.CommandComplete{CommandTag: fmt.Sprintf("SELECT %d", len(rows))})
+	}
+
+	if _, err := pg.lazyRows(&portal.rows, portal.Statement.Query, bindParams(portal)); err != nil {
+		return err
+	}
+
+	maxRows := int(m.MaxRows)
+	columns := portal.rows.Columns()
+
+	var sent int
+	for (maxRows <= 0 || sent < maxRows) && portal.rows.Next() {
+		dataRow, err := encodeRow(columns, portal.rows.Values(), portal.ResultFormats)
 		if err != nil {
 			return err
 		}
+		if err := pg.backend.Send(dataRow); err != nil {
+			return err
+		}
+		sent++
+	}
+	if err := portal.rows.Err(); err != nil {
+		return err
+	}
+
+	// If the row limit was hit exactly, there may be more rows to come; the
+	// client is expected to send another Execute against this same portal
+	// to continue the cursor, matching real Postgres's MaxRows semantics.
+	if maxRows > 0 && sent == maxRows {
+		return pg.backend.Send(&pgproto3.PortalSuspended{})
+	}
+
+	portal.rows.Close()
+	// The cursor is exhausted: drop it so a re-Execute against this portal
+	// opens a fresh one instead of reusing an already-closed Rows.
+	portal.rows = nil
+	return pg.backend.Send(&pgproto3.CommandComplete{CommandTag: fmt.Sprintf("SELECT %d", sent)})
+}
+
+func (pg *PGProtocol) handleClose(m *pgproto3.Close) error {
+	switch m.ObjectType {
+	case 'S':
+		pg.session.CloseStatement(m.Name)
+	case 'P':
+		if portal, ok := pg.session.Portal(m.Name); ok && portal.rows != nil {
+			portal.rows.Close()
+		}
+		pg.session.ClosePortal(m.Name)
+	default:
+		return fmt.Errorf("unknown Close object type: %q", string(m.ObjectType))
 	}
-	return nil
+	return pg.backend.Send(&pgproto3.CloseComplete{})
 }
@@ -0,0 +1,92 @@
+package pg
+
+import "testing"
+
+func TestBindParams(t *testing.T) {
+	stmt := &PreparedStatement{
+		Name:          "s1",
+		Query:         "SELECT * FROM t WHERE id = $1 AND name = $2",
+		ParameterOIDs: []uint32{23, 25},
+	}
+
+	tests := []struct {
+		name        string
+		portal      *Portal
+		wantOIDs    []uint32
+		wantFormats []int16
+		wantValues  [][]byte
+	}{
+		{
+			name: "per-parameter format codes",
+			portal: &Portal{
+				Statement:        stmt,
+				Parameters:       [][]byte{[]byte("42"), []byte("alice")},
+				ParameterFormats: []int16{0, 1},
+			},
+			wantOIDs:    []uint32{23, 25},
+			wantFormats: []int16{0, 1},
+			wantValues:  [][]byte{[]byte("42"), []byte("alice")},
+		},
+		{
+			name: "apply-to-all shorthand",
+			portal: &Portal{
+				Statement:        stmt,
+				Parameters:       [][]byte{[]byte("42"), []byte("alice")},
+				ParameterFormats: []int16{1},
+			},
+			wantOIDs:    []uint32{23, 25},
+			wantFormats: []int16{1, 1},
+			wantValues:  [][]byte{[]byte("42"), []byte("alice")},
+		},
+		{
+			name: "no format codes defaults to text",
+			portal: &Portal{
+				Statement:  stmt,
+				Parameters: [][]byte{[]byte("42"), []byte("alice")},
+			},
+			wantOIDs:    []uint32{23, 25},
+			wantFormats: []int16{0, 0},
+			wantValues:  [][]byte{[]byte("42"), []byte("alice")},
+		},
+		{
+			name: "more parameters than declared OIDs",
+			portal: &Portal{
+				Statement:  &PreparedStatement{Query: "SELECT $1, $2, $3", ParameterOIDs: []uint32{23}},
+				Parameters: [][]byte{[]byte("1"), []byte("2"), []byte("3")},
+			},
+			wantOIDs:    []uint32{23, 0, 0},
+			wantFormats: []int16{0, 0, 0},
+			wantValues:  [][]byte{[]byte("1"), []byte("2"), []byte("3")},
+		},
+		{
+			name: "null parameter",
+			portal: &Portal{
+				Statement:  stmt,
+				Parameters: [][]byte{nil, []byte("alice")},
+			},
+			wantOIDs:    []uint32{23, 25},
+			wantFormats: []int16{0, 0},
+			wantValues:  [][]byte{nil, []byte("alice")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := bindParams(tt.portal)
+			if len(params) != len(tt.wantValues) {
+				t.Fatalf("got %d params, want %d", len(params), len(tt.wantValues))
+			}
+			for i, p := range params {
+				if p.OID != tt.wantOIDs[i] {
+					t.Errorf("param %d: OID = %d, want %d", i, p.OID, tt.wantOIDs[i])
+				}
+				if p.Format != tt.wantFormats[i] {
+					t.Errorf("param %d: Format = %d, want %d", i, p.Format, tt.wantFormats[i])
+				}
+				if string(p.Value) != string(tt.wantValues[i]) {
+					t.Errorf("param %d: Value = %q, want %q", i, p.Value, tt.wantValues[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package pg
+
+import "context"
+
+// Column describes a single result column, mirroring the fields PostgreSQL's
+// RowDescription message needs to advertise.
+type Column struct {
+	Name         string
+	OID          uint32
+	TypeSize     int16
+	TypeModifier int32
+	Format       int16
+}
+
+// Rows is a cursor over the result of a query, modeled loosely on
+// database/sql.Rows. Columns returns the same Schema for the lifetime of the
+// cursor, so every Row it yields lines up with it positionally. Callers must
+// call Next before the first Values call and after every subsequent one, and
+// must call Close when done with the cursor.
+type Rows interface {
+	Columns() Schema
+	Next() bool
+	Values() Row
+	Err() error
+	Close() error
+}
+
+// Param is a single bound parameter from a Bind message: the OID its
+// prepared statement's Parse declared for it (0 if unspecified), the wire
+// format it arrived in (0 text, 1 binary), and its raw wire-format bytes
+// (nil for SQL NULL). The simple query protocol never carries parameters,
+// so QueryHandler.Query is called with params == nil in that path.
+type Param struct {
+	OID    uint32
+	Format int16
+	Value  []byte
+}
+
+// QueryHandler executes SQL text submitted by a client and returns the
+// resulting rows. Implementations back pgany with whatever data source they
+// like; the protocol layer only ever sees the Rows/Column abstraction.
+type QueryHandler interface {
+	Query(ctx context.Context, sess *Session, sql string, params []Param) (Rows, error)
+}
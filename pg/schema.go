@@ -0,0 +1,9 @@
+package pg
+
+// Schema is an ordered list of result columns. Order matters: it fixes the
+// column positions that RowDescription and every DataRow for a query must
+// agree on.
+type Schema []Column
+
+// Row is a single result row, with values positionally aligned to a Schema.
+type Row []any
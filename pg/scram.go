@@ -0,0 +1,357 @@
+package pg
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jerluc/pgany/pg/pgproto3"
+)
+
+// SCRAM-SHA-256 mechanism names, per RFC 5802 and PostgreSQL's channel
+// binding extension.
+const (
+	ScramSHA256     = "SCRAM-SHA-256"
+	ScramSHA256Plus = "SCRAM-SHA-256-PLUS"
+
+	// DefaultScramIterations matches the default Postgres uses for
+	// password_encryption=scram-sha-256.
+	DefaultScramIterations = 4096
+)
+
+// ScramCredentials are the values HashPassword derives from a password and
+// that an Authenticate exchange verifies a client against. The client's
+// plaintext password is never stored.
+type ScramCredentials struct {
+	Iterations int
+	Salt       []byte
+	StoredKey  []byte
+	ServerKey  []byte
+}
+
+// HashPassword derives SCRAM-SHA-256 credentials for password and packs them
+// into the string format expected by CredentialStore. user is not mixed
+// into the derivation; it is accepted so stores can key on it symmetrically
+// with Credentials.
+func HashPassword(user, password string, iterations int) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	saltedPassword := pbkdf2SHA256([]byte(password), salt, iterations)
+	clientKey := hmacSHA256(saltedPassword, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+	serverKey := hmacSHA256(saltedPassword, []byte("Server Key"))
+
+	return fmt.Sprintf("%d:%s:%s:%s",
+		iterations,
+		base64.StdEncoding.EncodeToString(salt),
+		base64.StdEncoding.EncodeToString(storedKey[:]),
+		base64.StdEncoding.EncodeToString(serverKey),
+	), nil
+}
+
+// ScramAuthenticator authenticates connections using SCRAM-SHA-256 (RFC
+// 5802), upgrading to SCRAM-SHA-256-PLUS channel binding when the
+// connection is over TLS.
+type ScramAuthenticator struct {
+	Store CredentialStore
+}
+
+func NewScramAuthenticator(store CredentialStore) *ScramAuthenticator {
+	return &ScramAuthenticator{Store: store}
+}
+
+func (a *ScramAuthenticator) Authenticate(ctx context.Context, backend *pgproto3.Backend, user string) error {
+	cbData, cbAvailable := channelBindingData(ctx)
+
+	mechanisms := []string{ScramSHA256}
+	if cbAvailable {
+		mechanisms = append([]string{ScramSHA256Plus}, mechanisms...)
+	}
+	if err := backend.Send(&pgproto3.AuthenticationSASL{AuthMechanisms: mechanisms}); err != nil {
+		return err
+	}
+
+	msgType, body, err := backend.ReceiveRaw()
+	if err != nil {
+		return err
+	}
+	if msgType != 'p' {
+		return fmt.Errorf("expected SASL initial response, got %q", string(msgType))
+	}
+	mechanism, clientFirstMessage, err := decodeSASLInitialResponse(body)
+	if err != nil {
+		return err
+	}
+
+	usesChannelBinding := mechanism == ScramSHA256Plus
+	if usesChannelBinding && !cbAvailable {
+		return fmt.Errorf("client requested %s without a TLS channel to bind to", ScramSHA256Plus)
+	}
+
+	clientFirstBare, clientNonce, clientGS2Header, err := parseClientFirstMessage(clientFirstMessage)
+	if err != nil {
+		return err
+	}
+	if err := validateGS2Header(clientGS2Header, usesChannelBinding, cbAvailable); err != nil {
+		return err
+	}
+
+	credsStr, err := a.Store.Credentials(ctx, user)
+	if err != nil {
+		return fmt.Errorf("unknown user %q: %w", user, err)
+	}
+	creds, err := parseScramCredentials(credsStr)
+	if err != nil {
+		return err
+	}
+
+	serverNonceSuffix := make([]byte, 18)
+	if _, err := rand.Read(serverNonceSuffix); err != nil {
+		return err
+	}
+	combinedNonce := clientNonce + base64.RawStdEncoding.EncodeToString(serverNonceSuffix)
+
+	serverFirstMessage := fmt.Sprintf("r=%s,s=%s,i=%d",
+		combinedNonce, base64.StdEncoding.EncodeToString(creds.Salt), creds.Iterations)
+	if err := backend.Send(&pgproto3.AuthenticationSASLContinue{Data: []byte(serverFirstMessage)}); err != nil {
+		return err
+	}
+
+	msgType, body, err = backend.ReceiveRaw()
+	if err != nil {
+		return err
+	}
+	if msgType != 'p' {
+		return fmt.Errorf("expected SASL response, got %q", string(msgType))
+	}
+	channelBindingB64, nonce, proofB64, err := parseClientFinalMessage(string(body))
+	if err != nil {
+		return err
+	}
+	if nonce != combinedNonce {
+		return fmt.Errorf("SCRAM nonce mismatch")
+	}
+
+	var boundChannelData []byte
+	if usesChannelBinding {
+		boundChannelData = cbData
+	}
+	wantChannelBinding := base64.StdEncoding.EncodeToString(append([]byte(clientGS2Header), boundChannelData...))
+	if channelBindingB64 != wantChannelBinding {
+		return fmt.Errorf("SCRAM channel binding mismatch")
+	}
+
+	clientFinalMessageWithoutProof := fmt.Sprintf("c=%s,r=%s", channelBindingB64, combinedNonce)
+	authMessage := clientFirstBare + "," + serverFirstMessage + "," + clientFinalMessageWithoutProof
+
+	proof, err := base64.StdEncoding.DecodeString(proofB64)
+	if err != nil || len(proof) != len(creds.StoredKey) {
+		return fmt.Errorf("invalid SCRAM client proof")
+	}
+	clientSignature := hmacSHA256(creds.StoredKey, []byte(authMessage))
+	clientKey := make([]byte, len(proof))
+	for i := range proof {
+		clientKey[i] = proof[i] ^ clientSignature[i]
+	}
+	computedStoredKey := sha256.Sum256(clientKey)
+	if !hmac.Equal(computedStoredKey[:], creds.StoredKey) {
+		return fmt.Errorf("authentication failed for user %q", user)
+	}
+
+	serverSignature := hmacSHA256(creds.ServerKey, []byte(authMessage))
+	serverFinalMessage := "v=" + base64.StdEncoding.EncodeToString(serverSignature)
+	if err := backend.Send(&pgproto3.AuthenticationSASLFinal{Data: []byte(serverFinalMessage)}); err != nil {
+		return err
+	}
+
+	return backend.Send(&pgproto3.AuthenticationOk{})
+}
+
+// channelBindingData returns the "tls-server-end-point" channel binding
+// data for the connection's TLS certificate (RFC 5929), if the connection
+// is over TLS.
+func channelBindingData(ctx context.Context) ([]byte, bool) {
+	cert, ok := ServerCertificateFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	sum := sha256.Sum256(cert)
+	return sum[:], true
+}
+
+// pbkdf2SHA256 implements RFC 2898's PBKDF2 with HMAC-SHA256, producing a
+// single 32-byte (one SHA-256 block) derived key, which is all SCRAM-SHA-256
+// ever needs.
+func pbkdf2SHA256(password, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, password)
+	mac.Write(salt)
+	mac.Write([]byte{0, 0, 0, 1})
+	u := mac.Sum(nil)
+
+	result := make([]byte, len(u))
+	copy(result, u)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(nil)
+		for j := range result {
+			result[j] ^= u[j]
+		}
+	}
+	return result
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// decodeSASLInitialResponse parses the body of the 'p' message a client
+// sends in response to AuthenticationSASL: a cstring mechanism name followed
+// by a length-prefixed blob of mechanism-specific data.
+func decodeSASLInitialResponse(body []byte) (mechanism string, data []byte, err error) {
+	idx := bytes.IndexByte(body, 0)
+	if idx < 0 {
+		return "", nil, fmt.Errorf("invalid SASL initial response")
+	}
+	mechanism = string(body[:idx])
+	rp := idx + 1
+
+	if len(body[rp:]) < 4 {
+		return "", nil, fmt.Errorf("invalid SASL initial response length")
+	}
+	dataLen := int32(binary.BigEndian.Uint32(body[rp:]))
+	rp += 4
+	if dataLen == -1 {
+		return mechanism, nil, nil
+	}
+	if len(body[rp:]) < int(dataLen) {
+		return "", nil, fmt.Errorf("invalid SASL initial response data")
+	}
+	return mechanism, body[rp : rp+int(dataLen)], nil
+}
+
+// parseClientFirstMessage splits a SCRAM client-first-message of the form
+// "<gs2-header>,n=<user>,r=<nonce>" into its bare part (everything after the
+// gs2-header), the client nonce, and the gs2-header itself (the client's
+// channel-binding flag and optional authzid, verbatim). The gs2-header is
+// returned rather than reconstructed so it can be checked against what the
+// negotiated mechanism and TLS state imply, and fed back into the
+// channel-binding check byte-for-byte as RFC 5802 requires.
+func parseClientFirstMessage(data []byte) (bare string, clientNonce string, gs2Header string, err error) {
+	s := string(data)
+	idx := strings.Index(s, ",")
+	if idx < 0 {
+		return "", "", "", fmt.Errorf("invalid client-first-message")
+	}
+	rest := s[idx+1:]
+	idx2 := strings.Index(rest, ",")
+	if idx2 < 0 {
+		return "", "", "", fmt.Errorf("invalid client-first-message")
+	}
+	gs2Header = s[:idx+1+idx2+1]
+	bare = rest[idx2+1:]
+
+	for _, field := range strings.Split(bare, ",") {
+		if strings.HasPrefix(field, "r=") {
+			clientNonce = field[2:]
+		}
+	}
+	if clientNonce == "" {
+		return "", "", "", fmt.Errorf("client-first-message missing nonce")
+	}
+	return bare, clientNonce, gs2Header, nil
+}
+
+// validateGS2Header checks the gs2-header a client embedded in its
+// client-first-message against the mechanism it selected and whether this
+// server actually supports channel binding. This is the check that detects
+// a MITM downgrade attack: an attacker stripping SCRAM-SHA-256-PLUS from
+// the AuthenticationSASL mechanism list (so the client never attempts
+// channel binding) would leave the client's gs2-header as "y,," — "I
+// support channel binding but believe you don't" — which a genuine,
+// untampered exchange against a channel-binding-capable server would never
+// produce, since the client would have seen PLUS in the list and used it.
+func validateGS2Header(gs2Header string, usesChannelBinding, cbAvailable bool) error {
+	switch {
+	case strings.HasPrefix(gs2Header, "p="):
+		if !usesChannelBinding || gs2Header != "p=tls-server-end-point,," {
+			return fmt.Errorf("SCRAM channel binding mismatch: unexpected gs2-header %q", gs2Header)
+		}
+	case strings.HasPrefix(gs2Header, "y,"):
+		if usesChannelBinding {
+			return fmt.Errorf("SCRAM channel binding mismatch: unexpected gs2-header %q", gs2Header)
+		}
+		if cbAvailable {
+			return fmt.Errorf("SCRAM channel binding downgrade detected: client supports channel binding but negotiated %s", ScramSHA256)
+		}
+	case strings.HasPrefix(gs2Header, "n,"):
+		if usesChannelBinding {
+			return fmt.Errorf("SCRAM channel binding mismatch: unexpected gs2-header %q", gs2Header)
+		}
+	default:
+		return fmt.Errorf("SCRAM channel binding mismatch: invalid gs2-header %q", gs2Header)
+	}
+	return nil
+}
+
+// parseClientFinalMessage splits a SCRAM client-final-message of the form
+// "c=<channel-binding>,r=<nonce>,p=<proof>".
+func parseClientFinalMessage(data string) (channelBinding, nonce, proof string, err error) {
+	for _, field := range strings.Split(data, ",") {
+		switch {
+		case strings.HasPrefix(field, "c="):
+			channelBinding = field[2:]
+		case strings.HasPrefix(field, "r="):
+			nonce = field[2:]
+		case strings.HasPrefix(field, "p="):
+			proof = field[2:]
+		}
+	}
+	if channelBinding == "" || nonce == "" || proof == "" {
+		return "", "", "", fmt.Errorf("invalid client-final-message")
+	}
+	return channelBinding, nonce, proof, nil
+}
+
+func parseScramCredentials(s string) (ScramCredentials, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 4 {
+		return ScramCredentials{}, fmt.Errorf("invalid stored SCRAM credentials")
+	}
+
+	iterations, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return ScramCredentials{}, fmt.Errorf("invalid SCRAM iteration count: %w", err)
+	}
+	salt, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ScramCredentials{}, fmt.Errorf("invalid SCRAM salt: %w", err)
+	}
+	storedKey, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return ScramCredentials{}, fmt.Errorf("invalid SCRAM stored key: %w", err)
+	}
+	serverKey, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return ScramCredentials{}, fmt.Errorf("invalid SCRAM server key: %w", err)
+	}
+
+	return ScramCredentials{
+		Iterations: iterations,
+		Salt:       salt,
+		StoredKey:  storedKey,
+		ServerKey:  serverKey,
+	}, nil
+}
@@ -0,0 +1,233 @@
+package pg
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jerluc/pgany/pg/pgproto3"
+)
+
+type mapCredentialStore map[string]string
+
+func (m mapCredentialStore) Credentials(ctx context.Context, user string) (string, error) {
+	creds, ok := m[user]
+	if !ok {
+		return "", fmt.Errorf("unknown user %q", user)
+	}
+	return creds, nil
+}
+
+// writeRawMessage writes a message frame (type byte, 4-byte big-endian
+// length, body) directly, bypassing pgproto3.FrontendMessage, since a raw
+// SASL initial response/response has no dedicated type in this package.
+func writeRawMessage(w net.Conn, msgType byte, body []byte) error {
+	buf := make([]byte, 0, 5+len(body))
+	buf = append(buf, msgType)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(4+len(body)))
+	buf = append(buf, body...)
+	_, err := w.Write(buf)
+	return err
+}
+
+// scramClientProof computes the client proof for the given salted password
+// and SCRAM auth message, mirroring RFC 5802's ClientProof computation.
+func scramClientProof(saltedPassword []byte, authMessage string) []byte {
+	clientKey := hmacSHA256(saltedPassword, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+	clientSignature := hmacSHA256(storedKey[:], []byte(authMessage))
+	proof := make([]byte, len(clientKey))
+	for i := range clientKey {
+		proof[i] = clientKey[i] ^ clientSignature[i]
+	}
+	return proof
+}
+
+// parseServerFirstMessage parses a SCRAM server-first-message of the form
+// "r=<nonce>,s=<salt-b64>,i=<iterations>".
+func parseServerFirstMessage(data string) (nonce string, salt []byte, iterations int, err error) {
+	for _, field := range strings.Split(data, ",") {
+		switch {
+		case strings.HasPrefix(field, "r="):
+			nonce = field[2:]
+		case strings.HasPrefix(field, "s="):
+			salt, err = base64.StdEncoding.DecodeString(field[2:])
+			if err != nil {
+				return "", nil, 0, err
+			}
+		case strings.HasPrefix(field, "i="):
+			iterations, err = strconv.Atoi(field[2:])
+			if err != nil {
+				return "", nil, 0, err
+			}
+		}
+	}
+	if nonce == "" || salt == nil {
+		return "", nil, 0, fmt.Errorf("invalid server-first-message: %q", data)
+	}
+	return nonce, salt, iterations, nil
+}
+
+func TestScramAuthenticateSuccess(t *testing.T) {
+	const user = "alice"
+	const password = "hunter2"
+
+	credsStr, err := HashPassword(user, password, DefaultScramIterations)
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	creds, err := parseScramCredentials(credsStr)
+	if err != nil {
+		t.Fatalf("parseScramCredentials: %v", err)
+	}
+
+	auth := NewScramAuthenticator(mapCredentialStore{user: credsStr})
+
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	backend := pgproto3.NewBackend(serverConn, serverConn)
+	frontend := pgproto3.NewFrontend(clientConn, clientConn)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- auth.Authenticate(context.Background(), backend, user)
+	}()
+
+	saslMsg, err := frontend.Receive()
+	if err != nil {
+		t.Fatalf("Receive(AuthenticationSASL): %v", err)
+	}
+	if _, ok := saslMsg.(*pgproto3.AuthenticationSASL); !ok {
+		t.Fatalf("expected AuthenticationSASL, got %T", saslMsg)
+	}
+
+	clientNonceBytes := make([]byte, 18)
+	if _, err := rand.Read(clientNonceBytes); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+	clientNonce := base64.RawStdEncoding.EncodeToString(clientNonceBytes)
+	gs2Header := "n,,"
+	clientFirstBare := fmt.Sprintf("n=,r=%s", clientNonce)
+	clientFirstMessage := gs2Header + clientFirstBare
+
+	initialResponse := append([]byte(ScramSHA256), 0)
+	initialResponse = binary.BigEndian.AppendUint32(initialResponse, uint32(len(clientFirstMessage)))
+	initialResponse = append(initialResponse, clientFirstMessage...)
+	if err := writeRawMessage(clientConn, 'p', initialResponse); err != nil {
+		t.Fatalf("write SASL initial response: %v", err)
+	}
+
+	continueMsg, err := frontend.Receive()
+	if err != nil {
+		t.Fatalf("Receive(AuthenticationSASLContinue): %v", err)
+	}
+	cont, ok := continueMsg.(*pgproto3.AuthenticationSASLContinue)
+	if !ok {
+		t.Fatalf("expected AuthenticationSASLContinue, got %T", continueMsg)
+	}
+	combinedNonce, salt, iterations, err := parseServerFirstMessage(string(cont.Data))
+	if err != nil {
+		t.Fatalf("parseServerFirstMessage: %v", err)
+	}
+	if iterations != creds.Iterations {
+		t.Fatalf("iterations = %d, want %d", iterations, creds.Iterations)
+	}
+
+	saltedPassword := pbkdf2SHA256([]byte(password), salt, iterations)
+	channelBinding := base64.StdEncoding.EncodeToString([]byte(gs2Header))
+	clientFinalWithoutProof := fmt.Sprintf("c=%s,r=%s", channelBinding, combinedNonce)
+	authMessage := clientFirstBare + "," + string(cont.Data) + "," + clientFinalWithoutProof
+	proof := scramClientProof(saltedPassword, authMessage)
+
+	clientFinalMessage := fmt.Sprintf("%s,p=%s", clientFinalWithoutProof, base64.StdEncoding.EncodeToString(proof))
+	if err := writeRawMessage(clientConn, 'p', []byte(clientFinalMessage)); err != nil {
+		t.Fatalf("write SASL response: %v", err)
+	}
+
+	finalMsg, err := frontend.Receive()
+	if err != nil {
+		t.Fatalf("Receive(AuthenticationSASLFinal): %v", err)
+	}
+	if _, ok := finalMsg.(*pgproto3.AuthenticationSASLFinal); !ok {
+		t.Fatalf("expected AuthenticationSASLFinal, got %T", finalMsg)
+	}
+
+	okMsg, err := frontend.Receive()
+	if err != nil {
+		t.Fatalf("Receive(AuthenticationOk): %v", err)
+	}
+	if _, ok := okMsg.(*pgproto3.AuthenticationOk); !ok {
+		t.Fatalf("expected AuthenticationOk, got %T", okMsg)
+	}
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+}
+
+// TestValidateGS2HeaderRejectsChannelBindingDowngrade exercises the
+// downgrade this check exists to catch: a client that supports channel
+// binding but, because a MITM stripped SCRAM-SHA-256-PLUS from the
+// mechanism list, negotiates plain SCRAM-SHA-256 with a "y,," gs2-header.
+func TestValidateGS2HeaderRejectsChannelBindingDowngrade(t *testing.T) {
+	tests := []struct {
+		name               string
+		gs2Header          string
+		usesChannelBinding bool
+		cbAvailable        bool
+		wantErr            bool
+	}{
+		{"plain mechanism, no channel binding available", "n,,", false, false, false},
+		{"plain mechanism, channel binding available, client opts out", "n,,", false, true, false},
+		{"PLUS mechanism, correct header", "p=tls-server-end-point,,", true, true, false},
+		{"PLUS mechanism, wrong cb-name", "p=other,,", true, true, true},
+		{"plain mechanism claimed but p= header", "p=tls-server-end-point,,", false, true, true},
+		{"downgrade: y header but server supports channel binding", "y,,", false, true, true},
+		{"y header, server genuinely lacks channel binding", "y,,", false, false, false},
+		{"PLUS mechanism but y header", "y,,", true, true, true},
+		{"malformed header", "bogus", false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGS2Header(tt.gs2Header, tt.usesChannelBinding, tt.cbAvailable)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateGS2Header(%q, %v, %v) = %v, wantErr %v",
+					tt.gs2Header, tt.usesChannelBinding, tt.cbAvailable, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseClientFirstMessage(t *testing.T) {
+	bare, nonce, gs2Header, err := parseClientFirstMessage([]byte("n,,n=,r=abc123"))
+	if err != nil {
+		t.Fatalf("parseClientFirstMessage: %v", err)
+	}
+	if gs2Header != "n,," {
+		t.Errorf("gs2Header = %q, want %q", gs2Header, "n,,")
+	}
+	if bare != "n=,r=abc123" {
+		t.Errorf("bare = %q, want %q", bare, "n=,r=abc123")
+	}
+	if nonce != "abc123" {
+		t.Errorf("nonce = %q, want %q", nonce, "abc123")
+	}
+
+	_, _, gs2Header, err = parseClientFirstMessage([]byte("p=tls-server-end-point,,n=,r=xyz"))
+	if err != nil {
+		t.Fatalf("parseClientFirstMessage: %v", err)
+	}
+	if gs2Header != "p=tls-server-end-point,," {
+		t.Errorf("gs2Header = %q, want %q", gs2Header, "p=tls-server-end-point,,")
+	}
+}
@@ -1,14 +1,43 @@
 package pg
 
 import (
+	"crypto/tls"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 	"net"
 	"net/url"
 )
 
+// PGProtoServerConfig configures a PGProtoServer.
+type PGProtoServerConfig struct {
+	// BindAddress is the address to listen on, e.g. "tcp://127.0.0.1:5432"
+	// or "unix:///tmp/.s.PGSQL.5432".
+	BindAddress string
+
+	// TLSConfig, when non-nil, is offered to clients that send an
+	// SSLRequest during startup.
+	TLSConfig *tls.Config
+
+	// RequireTLS rejects any client that does not negotiate TLS. Requires
+	// TLSConfig to be set.
+	RequireTLS bool
+
+	// Authenticator, when non-nil, authenticates connections after the
+	// StartupMessage. When nil, any client is accepted (trust
+	// authentication).
+	Authenticator Authenticator
+
+	// QueryHandler executes client queries. When nil, the server responds
+	// to every query with a fixed placeholder result set.
+	QueryHandler QueryHandler
+}
+
 type PGProtoServer struct {
-	bindAddr net.Addr
+	bindAddr      net.Addr
+	tlsConfig     *tls.Config
+	requireTLS    bool
+	authenticator Authenticator
+	queryHandler  QueryHandler
 }
 
 func (s *PGProtoServer) handleConnection(clientConn net.Conn) {
@@ -17,7 +46,12 @@ func (s *PGProtoServer) handleConnection(clientConn net.Conn) {
 	})
 
 	clientLogger.Info("Client connected")
-	pg := &PGProtocol{clientConn, clientLogger}
+	pg := NewPGProtocol(clientConn, clientLogger, PGProtocolConfig{
+		TLSConfig:     s.tlsConfig,
+		RequireTLS:    s.requireTLS,
+		Authenticator: s.authenticator,
+		QueryHandler:  s.queryHandler,
+	})
 	if err := pg.Loop(); err != nil {
 		clientLogger.Error(err)
 	}
@@ -63,10 +97,13 @@ func parseAddr(addr string) (net.Addr, error) {
 	return bindAddr, nil
 }
 
-func NewPGProtoServer(addr string) (*PGProtoServer, error) {
-	bindAddr, err := parseAddr(addr)
+func NewPGProtoServer(cfg PGProtoServerConfig) (*PGProtoServer, error) {
+	bindAddr, err := parseAddr(cfg.BindAddress)
 	if err != nil {
 		return nil, err
 	}
-	return &PGProtoServer{bindAddr}, nil
+	if cfg.RequireTLS && cfg.TLSConfig == nil {
+		return nil, fmt.Errorf("RequireTLS requires a TLSConfig")
+	}
+	return &PGProtoServer{bindAddr, cfg.TLSConfig, cfg.RequireTLS, cfg.Authenticator, cfg.QueryHandler}, nil
 }
@@ -0,0 +1,116 @@
+package pg
+
+// PreparedStatement is a named, parsed SQL statement produced by a Parse
+// message.
+type PreparedStatement struct {
+	Name          string
+	Query         string
+	ParameterOIDs []uint32
+
+	// rows is the result cursor opened by a Describe of this statement
+	// before any Bind against it, so that the Bind which follows can adopt
+	// it as the new portal's cursor instead of running the query again.
+	rows Rows
+}
+
+// Portal is a prepared statement bound to a concrete set of parameter
+// values, produced by a Bind message.
+type Portal struct {
+	Name             string
+	Statement        *PreparedStatement
+	Parameters       [][]byte
+	ParameterFormats []int16
+	ResultFormats    []int16
+
+	// rows is the result cursor for this portal: adopted from the statement
+	// at Bind, or opened lazily by the first Describe or Execute against it,
+	// so that a suspended portal can be resumed by a later Execute without
+	// re-running the query.
+	rows Rows
+}
+
+// TxState is the status of a session's current SQL transaction, as reported
+// in the ReadyForQuery message.
+type TxState byte
+
+const (
+	// TxIdle means the session is not in a transaction block.
+	TxIdle TxState = 'I'
+	// TxActive means the session is in a transaction block.
+	TxActive TxState = 'T'
+	// TxFailed means the session is in a transaction block that has seen a
+	// failed command. It lasts until COMMIT or ROLLBACK ends the block;
+	// commands sent in this state are not rejected.
+	TxFailed TxState = 'E'
+)
+
+// Session holds the state of a single client connection that spans
+// individual messages: its authenticated user, its named prepared
+// statements and portals, and its transaction state.
+type Session struct {
+	User string
+
+	preparedStatements map[string]*PreparedStatement
+	portals            map[string]*Portal
+	txState            TxState
+}
+
+func NewSession(user string) *Session {
+	return &Session{
+		User:               user,
+		preparedStatements: make(map[string]*PreparedStatement),
+		portals:            make(map[string]*Portal),
+		txState:            TxIdle,
+	}
+}
+
+// TxState returns the session's current transaction state.
+func (s *Session) TxState() TxState {
+	return s.txState
+}
+
+// BeginTx moves the session into a transaction block.
+func (s *Session) BeginTx() {
+	s.txState = TxActive
+}
+
+// EndTx commits or rolls back the session's transaction block, returning it
+// to idle.
+func (s *Session) EndTx() {
+	s.txState = TxIdle
+}
+
+// FailTx marks an active transaction block as failed. It is a no-op outside
+// of a transaction block, since in that case the failed command's implicit
+// transaction has already ended.
+func (s *Session) FailTx() {
+	if s.txState == TxActive {
+		s.txState = TxFailed
+	}
+}
+
+func (s *Session) AddPreparedStatement(stmt *PreparedStatement) {
+	s.preparedStatements[stmt.Name] = stmt
+}
+
+func (s *Session) PreparedStatement(name string) (*PreparedStatement, bool) {
+	stmt, ok := s.preparedStatements[name]
+	return stmt, ok
+}
+
+func (s *Session) CloseStatement(name string) {
+	delete(s.preparedStatements, name)
+}
+
+func (s *Session) AddPortal(portal *Portal) {
+	s.portals[portal.Name] = portal
+}
+
+func (s *Session) Portal(name string) (*Portal, bool) {
+	portal, ok := s.portals[name]
+	return portal, ok
+}
+
+func (s *Session) ClosePortal(name string) {
+	delete(s.portals, name)
+}